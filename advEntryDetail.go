@@ -108,6 +108,18 @@ const (
 	DebitSummary = 88
 )
 
+// advCreditOrDebit returns "C" for ADV credit transaction codes and "D" for ADV debits. Unlike
+// other entries, odd ADV codes are credits and even codes are debits.
+func advCreditOrDebit(code int) string {
+	switch code {
+	case CreditForDebitsOriginated, CreditForCreditsReceived, CreditForCreditsRejected, CreditSummary:
+		return "C"
+	case DebitForCreditsOriginated, DebitForDebitsReceived, DebitForDebitsRejectedBatches, DebitSummary:
+		return "D"
+	}
+	return ""
+}
+
 // NewADVEntryDetail returns a new ADVEntryDetail with default values for non exported fields
 func NewADVEntryDetail() *ADVEntryDetail {
 	entry := &ADVEntryDetail{
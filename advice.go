@@ -0,0 +1,199 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ADVAccount is the settlement account of a DFI which receives advices of its ACH activity.
+type ADVAccount struct {
+	// RoutingNumber of the institution holding the settlement account, which can be a correspondent of the DFI
+	RoutingNumber string `json:"routingNumber"`
+	AccountNumber string `json:"accountNumber"`
+	// Name of the DFI, written as the ADV entry's IndividualName
+	Name string `json:"name"`
+}
+
+// ADVOpts are the options for GenerateADVFiles.
+type ADVOpts struct {
+	// ACHOperatorRoutingNumber is the 9 digit routing number of the ACH Operator sending the advices
+	ACHOperatorRoutingNumber string `json:"achOperatorRoutingNumber"`
+	ACHOperatorName          string `json:"achOperatorName"`
+
+	// Accounts are keyed by the 9 digit routing number of each DFI which requested advices.
+	// Activity of other DFIs is skipped.
+	Accounts map[string]ADVAccount `json:"accounts"`
+
+	// SettlementDate sets the EffectiveEntryDate and JulianDay of advices, defaults to today.
+	SettlementDate time.Time `json:"settlementDate"`
+}
+
+type advKey struct {
+	file            int
+	transactionCode int
+}
+
+// GenerateADVFiles summarizes the ACH activity of settled forward and return files into an ADV file for
+// each DFI in opts.Accounts, sorted by routing number. Each file has one ADV batch with an entry per
+// source file and accounting transaction code:
+//
+//   - ODFIs are debited for credits originated (82) and credited for debits originated (81)
+//   - RDFIs are credited for credits received (83) and debited for debits received (84)
+//
+// Returns are counted as entries originated by the returning DFI. Entries without an amount, such as
+// prenotes and Notifications of Change, are skipped. An entry's FileIdentification is its source
+// file's FileCreationTime and FileIDModifier, and entries are numbered in order from 1.
+func GenerateADVFiles(files []*File, opts ADVOpts) ([]*File, error) {
+	if err := CheckRoutingNumber(opts.ACHOperatorRoutingNumber); err != nil {
+		return nil, fmt.Errorf("ADV: ACH Operator routing number: %v", err)
+	}
+	if len(opts.Accounts) == 0 {
+		return nil, errors.New("ADV: no accounts to advise")
+	}
+	settlement := opts.SettlementDate
+	if settlement.IsZero() {
+		settlement = time.Now()
+	}
+
+	// totals of each advised DFI
+	totals := make(map[string]map[advKey]int)
+	add := func(routingNumber string, file, transactionCode, amount int) {
+		if amount <= 0 {
+			return
+		}
+		if _, exists := opts.Accounts[routingNumber]; !exists {
+			return
+		}
+		if totals[routingNumber] == nil {
+			totals[routingNumber] = make(map[advKey]int)
+		}
+		totals[routingNumber][advKey{file: file, transactionCode: transactionCode}] += amount
+	}
+	activity := func(file int, odfi, rdfi string, transactionCode, amount int) {
+		switch creditOrDebit(transactionCode) {
+		case "C":
+			add(odfi, file, DebitForCreditsOriginated, amount)
+			add(rdfi, file, CreditForCreditsReceived, amount)
+		case "D":
+			add(odfi, file, CreditForDebitsOriginated, amount)
+			add(rdfi, file, DebitForDebitsReceived, amount)
+		}
+	}
+
+	for i, file := range files {
+		if file == nil {
+			continue
+		}
+		for _, b := range file.Batches {
+			bh := b.GetHeader()
+			if bh.StandardEntryClassCode == ADV {
+				continue
+			}
+			odfi := withCheckDigit(bh.ODFIIdentificationField())
+			for _, ed := range b.GetEntries() {
+				activity(i, odfi, ed.RDFIIdentificationField()+ed.CheckDigit, ed.TransactionCode, ed.Amount)
+			}
+		}
+		for j := range file.IATBatches {
+			bh := file.IATBatches[j].GetHeader()
+			odfi := withCheckDigit(bh.ODFIIdentificationField())
+			for _, ed := range file.IATBatches[j].Entries {
+				activity(i, odfi, ed.RDFIIdentificationField()+ed.CheckDigit, ed.TransactionCode, ed.Amount)
+			}
+		}
+	}
+
+	routingNumbers := make([]string, 0, len(totals))
+	for rn := range totals {
+		routingNumbers = append(routingNumbers, rn)
+	}
+	sort.Strings(routingNumbers)
+
+	var out []*File
+	for _, rn := range routingNumbers {
+		account := opts.Accounts[rn]
+		keys := make([]advKey, 0, len(totals[rn]))
+		for k := range totals[rn] {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].file != keys[j].file {
+				return keys[i].file < keys[j].file
+			}
+			return keys[i].transactionCode < keys[j].transactionCode
+		})
+		if len(keys) > 9999 {
+			return nil, fmt.Errorf("ADV: %d advices for %s exceed the 4 digit sequence number", len(keys), rn)
+		}
+
+		bh := NewBatchHeader()
+		bh.ServiceClassCode = AutomatedAccountingAdvices
+		bh.StandardEntryClassCode = ADV
+		bh.CompanyName = opts.ACHOperatorName
+		bh.CompanyIdentification = opts.ACHOperatorRoutingNumber
+		bh.CompanyEntryDescription = "ADV"
+		bh.EffectiveEntryDate = settlement.Format("060102")
+		bh.ODFIIdentification = opts.ACHOperatorRoutingNumber[:8]
+		bh.OriginatorStatusCode = 0
+
+		batch := NewBatchADV(bh)
+		for seq, k := range keys {
+			source := files[k.file]
+			ed := NewADVEntryDetail()
+			ed.TransactionCode = k.transactionCode
+			ed.SetRDFI(account.RoutingNumber)
+			ed.DFIAccountNumber = account.AccountNumber
+			ed.Amount = totals[rn][k]
+			ed.AdviceRoutingNumber = rn
+			ed.FileIdentification = source.Header.FileCreationTime + source.Header.FileIDModifier
+			ed.IndividualName = account.Name
+			ed.ACHOperatorRoutingNumber = opts.ACHOperatorRoutingNumber[:8]
+			ed.JulianDay = settlement.YearDay()
+			ed.SequenceNumber = seq + 1
+			batch.AddADVEntry(ed)
+		}
+		if err := batch.Create(); err != nil {
+			return nil, fmt.Errorf("ADV for %s: %w", rn, err)
+		}
+
+		file := NewFile()
+		file.Header = NewFileHeader()
+		file.Header.ImmediateDestination = rn
+		file.Header.ImmediateDestinationName = account.Name
+		file.Header.ImmediateOrigin = opts.ACHOperatorRoutingNumber
+		file.Header.ImmediateOriginName = opts.ACHOperatorName
+		file.Header.FileCreationDate = settlement.Format("060102")
+		file.Header.FileCreationTime = settlement.Format("1504")
+		file.AddBatch(batch)
+		if err := file.Create(); err != nil {
+			return nil, fmt.Errorf("ADV for %s: %w", rn, err)
+		}
+		out = append(out, file)
+	}
+	return out, nil
+}
+
+// withCheckDigit returns the 9 digit routing number of an 8 digit DFI identification
+func withCheckDigit(identification string) string {
+	return identification + strconv.Itoa(CalculateCheckDigit(identification))
+}
@@ -0,0 +1,120 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateADVFiles(t *testing.T) {
+	credits := mockFilePPD(t)
+	credits.Header.FileCreationTime = "0930"
+
+	bh := mockBatchPPDHeader()
+	bh.ServiceClassCode = DebitsOnly
+	batch := NewBatchPPD(bh)
+	for i, code := range []int{CheckingDebit, CheckingPrenoteDebit, SavingsDebit} {
+		ed := mockPPDEntryDetail()
+		ed.TransactionCode = code
+		ed.Amount = 2500
+		if code == CheckingPrenoteDebit {
+			ed.Amount = 0
+		}
+		ed.SetTraceNumber(bh.ODFIIdentification, i+1)
+		batch.AddEntry(ed)
+	}
+	require.NoError(t, batch.Create())
+	debits := NewFile()
+	debits.SetHeader(mockFileHeader())
+	debits.Header.FileCreationTime = "1400"
+	debits.Header.FileIDModifier = "B"
+	debits.AddBatch(batch)
+	require.NoError(t, debits.Create())
+
+	settlement := time.Date(2026, time.April, 16, 17, 0, 0, 0, time.UTC)
+	opts := ADVOpts{
+		ACHOperatorRoutingNumber: "011000015",
+		ACHOperatorName:          "FEDACH",
+		Accounts: map[string]ADVAccount{
+			"231380104": {RoutingNumber: "231380104", AccountNumber: "5550001", Name: "Citadel"},
+			"121042882": {RoutingNumber: "011000015", AccountNumber: "7770001", Name: "Wells Fargo"},
+		},
+		SettlementDate: settlement,
+	}
+	advs, err := GenerateADVFiles([]*File{credits, nil, debits}, opts)
+	require.NoError(t, err)
+	require.Len(t, advs, 2)
+
+	// the ODFI's advice
+	odfi := advs[0]
+	require.True(t, odfi.IsADV())
+	require.Equal(t, "121042882", odfi.Header.ImmediateDestination)
+	require.Equal(t, "260416", odfi.Header.FileCreationDate)
+	require.Equal(t, "260416", odfi.Batches[0].GetHeader().EffectiveEntryDate)
+
+	entries := odfi.Batches[0].GetADVEntries()
+	require.Len(t, entries, 2)
+	require.Equal(t, DebitForCreditsOriginated, entries[0].TransactionCode)
+	require.Equal(t, 100000000, entries[0].Amount)
+	require.Equal(t, "0930A", entries[0].FileIdentification)
+	require.Equal(t, CreditForDebitsOriginated, entries[1].TransactionCode)
+	require.Equal(t, 5000, entries[1].Amount)
+	require.Equal(t, "1400B", entries[1].FileIdentification)
+	for i, ed := range entries {
+		require.Equal(t, "01100001", ed.RDFIIdentification)
+		require.Equal(t, "7770001", ed.DFIAccountNumber)
+		require.Equal(t, "121042882", ed.AdviceRoutingNumber)
+		require.Equal(t, "01100001", ed.ACHOperatorRoutingNumber)
+		require.Equal(t, 106, ed.JulianDay)
+		require.Equal(t, i+1, ed.SequenceNumber)
+	}
+	require.Equal(t, 100000000, odfi.ADVControl.TotalDebitEntryDollarAmountInFile)
+	require.Equal(t, 5000, odfi.ADVControl.TotalCreditEntryDollarAmountInFile)
+
+	// the RDFI's advice
+	rdfi := advs[1]
+	require.Equal(t, "231380104", rdfi.Header.ImmediateDestination)
+	entries = rdfi.Batches[0].GetADVEntries()
+	require.Len(t, entries, 2)
+	require.Equal(t, CreditForCreditsReceived, entries[0].TransactionCode)
+	require.Equal(t, DebitForDebitsReceived, entries[1].TransactionCode)
+	require.Equal(t, 5000, entries[1].Amount)
+
+	// advices are valid ADV files
+	read, err := NewReader(bytes.NewReader(writeFileBytes(t, rdfi))).Read()
+	require.NoError(t, err)
+	require.NoError(t, read.Validate())
+	require.Equal(t, rdfi.ADVControl, read.ADVControl)
+
+	// only DFIs which requested advices receive them
+	delete(opts.Accounts, "231380104")
+	advs, err = GenerateADVFiles([]*File{credits, debits, odfi}, opts)
+	require.NoError(t, err)
+	require.Len(t, advs, 1)
+	require.Len(t, advs[0].Batches[0].GetADVEntries(), 2)
+
+	opts.ACHOperatorRoutingNumber = "011000016"
+	_, err = GenerateADVFiles([]*File{credits}, opts)
+	require.Error(t, err)
+	_, err = GenerateADVFiles([]*File{credits}, ADVOpts{ACHOperatorRoutingNumber: "011000015"})
+	require.Error(t, err)
+}
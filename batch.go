@@ -1016,7 +1016,7 @@ func (batch *Batch) addendaFieldInclusionReturn(entry *EntryDetail) error {
 		// used to zero accounting entries.
 		//
 		// See: https://github.com/moov-io/ach/issues/1010
-		if entry.IndividualName == offsetIndividualName {
+		if entry.Offset || entry.IndividualName == offsetIndividualName {
 			return nil
 		}
 		return batch.Error("Addenda99", ErrFieldInclusion)
@@ -1148,8 +1148,125 @@ func (b *Batch) WithOffset(off *Offset) {
 	b.offset = off
 }
 
+// RemoveOffsets clears the Offset information from a Batch and removes any offset records
+// it previously created. Call Create afterwards to re-tabulate the BatchControl.
+func (b *Batch) RemoveOffsets() {
+	b.removeOffsets()
+}
+
+// offsetBatcher is implemented by every Batcher embedding Batch and lets File balance them
+// without widening the Batcher interface.
+type offsetBatcher interface {
+	currentOffset() *Offset
+	removeOffsets(accounts ...*Offset)
+}
+
+func (b *Batch) currentOffset() *Offset {
+	if b == nil {
+		return nil
+	}
+	return b.offset
+}
+
+// removeOffsets removes the Batch's offset records, including unmarked records posting to any of
+// accounts, and clears its Offset.
+func (b *Batch) removeOffsets(accounts ...*Offset) {
+	if b == nil {
+		return
+	}
+	b.removeOffsetEntries(accounts...)
+	b.offset = nil
+}
+
 const offsetIndividualName = "OFFSET"
 
+// offsetEntries reports which of the Batch's entries are balancing offset records.
+//
+// Entries created by upsertOffsets are marked explicitly. Entries read back without that
+// marker (e.g. from a Nacha formatted file) are considered offsets when they are named OFFSET
+// and either post to the Batch's offset account or one of accounts, or are the last entries of
+// the Batch and balance the entries before them.
+func (b *Batch) offsetEntries(accounts ...*Offset) []bool {
+	accounts = append(accounts, b.offset)
+	named := func(ed *EntryDetail) bool {
+		return ed != nil && strings.EqualFold(strings.TrimSpace(ed.IndividualName), offsetIndividualName)
+	}
+
+	offsets := make([]bool, len(b.Entries))
+	for i, ed := range b.Entries {
+		if ed == nil {
+			continue
+		}
+		if ed.Offset {
+			offsets[i] = true
+			continue
+		}
+		if !named(ed) {
+			continue
+		}
+		for _, off := range accounts {
+			if off != nil && ed.RDFIIdentification == aba8(off.RoutingNumber) &&
+				strings.TrimSpace(ed.DFIAccountNumber) == strings.TrimSpace(off.AccountNumber) {
+				offsets[i] = true
+			}
+		}
+	}
+
+	// at most a debit and a credit offset end the batch
+	start := len(b.Entries)
+	for start > 0 && len(b.Entries)-start < 2 && named(b.Entries[start-1]) {
+		start--
+	}
+	credits, debits := 0, 0
+	for i := 0; i < start; i++ {
+		if offsets[i] || b.Entries[i] == nil {
+			continue
+		}
+		switch b.Entries[i].CreditOrDebit() {
+		case "C":
+			credits += b.Entries[i].Amount
+		case "D":
+			debits += b.Entries[i].Amount
+		}
+	}
+	for i := start; i < len(b.Entries); i++ {
+		ed := b.Entries[i]
+		if ed.Amount <= 0 {
+			continue
+		}
+		switch ed.CreditOrDebit() {
+		case "C":
+			offsets[i] = offsets[i] || ed.Amount == debits
+		case "D":
+			offsets[i] = offsets[i] || ed.Amount == credits
+		}
+	}
+	return offsets
+}
+
+// removeOffsetEntries removes offset records from the Batch and backs their amounts
+// out of the BatchControl.
+func (b *Batch) removeOffsetEntries(accounts ...*Offset) {
+	offsets := b.offsetEntries(accounts...)
+	entries := b.Entries[:0]
+	for i, ed := range b.Entries {
+		if !offsets[i] {
+			entries = append(entries, ed)
+			continue
+		}
+		if ed.CreditOrDebit() == "C" {
+			b.Control.TotalCreditEntryDollarAmount -= ed.Amount
+		} else {
+			b.Control.TotalDebitEntryDollarAmount -= ed.Amount
+		}
+		b.Control.EntryAddendaCount -= 1
+	}
+	for i := len(entries); i < len(b.Entries); i++ {
+		b.Entries[i] = nil
+	}
+	b.Entries = entries
+}
+
 func (b *Batch) upsertOffsets() error {
 	if b == nil || b.offset == nil {
 		return nil
@@ -1159,42 +1276,21 @@ func (b *Batch) upsertOffsets() error {
 	}
 
 	// remove any Offset records already on the batch
-	for i := 0; i < len(b.Entries); i++ {
-		// TODO(adam): Should we remove this based on checking the last element is
-		// debit/credit and sums to all the other elements (which are mutually exclusive to
-		// the last record being debit or credit)?
-		// See: https://github.com/moov-io/ach/issues/540
-		if strings.EqualFold(b.Entries[i].IndividualName, offsetIndividualName) {
-			// fixup BatchControl records for our conditional after this for loop
-			if b.Entries[i].TransactionCode == CheckingCredit || b.Entries[i].TransactionCode == SavingsCredit {
-				b.Control.TotalCreditEntryDollarAmount -= b.Entries[i].Amount
-			} else {
-				b.Control.TotalDebitEntryDollarAmount -= b.Entries[i].Amount
-			}
-			// remove the EntryDetail
-			b.Control.EntryAddendaCount -= 1
-			b.Entries = append(b.Entries[:i], b.Entries[i+i:]...)
-			i--
-		}
-	}
+	b.removeOffsetEntries()
 
 	// Make sure the offset account type is valid
 	if err := b.offset.AccountType.validate(); err != nil {
 		return err
 	}
 
+	debitCode, creditCode := b.offset.AccountType.transactionCodes()
 	offsetCount := 1
 
 	// Create our debit offset EntryDetail
 	debitED := createOffsetEntryDetail(b.offset, b)
 	debitED.TraceNumber = fmt.Sprintf("%15.15d", lastTraceNumber(b.Entries)+offsetCount)
 	debitED.Amount = b.Control.TotalCreditEntryDollarAmount
-	switch b.offset.AccountType {
-	case OffsetChecking:
-		debitED.TransactionCode = CheckingDebit
-	case OffsetSavings:
-		debitED.TransactionCode = SavingsDebit
-	}
+	debitED.TransactionCode = debitCode
 	if debitED.Amount == 0 {
 		debitED = nil // zero out so we don't add an empty OFFSET EntryDetail
 	} else {
@@ -1205,12 +1301,7 @@ func (b *Batch) upsertOffsets() error {
 	creditED := createOffsetEntryDetail(b.offset, b)
 	creditED.TraceNumber = fmt.Sprintf("%15.15d", lastTraceNumber(b.Entries)+offsetCount)
 	creditED.Amount = b.Control.TotalDebitEntryDollarAmount
-	switch b.offset.AccountType {
-	case OffsetChecking:
-		creditED.TransactionCode = CheckingCredit
-	case OffsetSavings:
-		creditED.TransactionCode = SavingsCredit
-	}
+	creditED.TransactionCode = creditCode
 	if creditED.Amount == 0 {
 		creditED = nil // zero out so we don't add an empty OFFSET EntryDetail
 	}
@@ -1242,6 +1333,7 @@ func createOffsetEntryDetail(off *Offset, batch *Batch) *EntryDetail {
 	ed.IdentificationNumber = "" // left empty
 	ed.IndividualName = offsetIndividualName
 	ed.DiscretionaryData = batch.offset.Description
+	ed.Offset = true
 	if len(batch.Entries) > 0 {
 		ed.Category = batch.Entries[0].Category
 	}
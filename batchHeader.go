@@ -276,6 +276,12 @@ func (bh *BatchHeader) Equal(other *BatchHeader) bool {
 	return true
 }
 
+// isSameDay returns true when the CompanyDescriptiveDate follows the "SDHHMM" convention
+// which denotes the intent for same-day settlement.
+func (bh *BatchHeader) isSameDay() bool {
+	return strings.HasPrefix(strings.ToUpper(bh.CompanyDescriptiveDate), "SD")
+}
+
 // SetValidation stores ValidateOpts on the BatchHeader which are to be used to override
 // the default NACHA validation rules.
 func (bh *BatchHeader) SetValidation(opts *ValidateOpts) {
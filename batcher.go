@@ -63,15 +63,31 @@ type Offset struct {
 type OffsetAccountType string
 
 const (
-	OffsetChecking OffsetAccountType = "checking"
-	OffsetSavings  OffsetAccountType = "savings"
+	OffsetChecking      OffsetAccountType = "checking"
+	OffsetSavings       OffsetAccountType = "savings"
+	OffsetGeneralLedger OffsetAccountType = "generalLedger"
+	OffsetLoan          OffsetAccountType = "loan"
 )
 
 func (t OffsetAccountType) validate() error {
 	switch t {
-	case OffsetChecking, OffsetSavings:
+	case OffsetChecking, OffsetSavings, OffsetGeneralLedger, OffsetLoan:
 		return nil
 	default:
 		return fmt.Errorf("unknown offset account type: %s", t)
 	}
 }
+
+// transactionCodes returns the debit and credit TransactionCode used for offset records of this account type.
+func (t OffsetAccountType) transactionCodes() (debit int, credit int) {
+	switch t {
+	case OffsetSavings:
+		return SavingsDebit, SavingsCredit
+	case OffsetGeneralLedger:
+		return GLDebit, GLCredit
+	case OffsetLoan:
+		return LoanDebit, LoanCredit
+	default:
+		return CheckingDebit, CheckingCredit
+	}
+}
@@ -0,0 +1,191 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Canonicalize sorts a File into a canonical order so that logically identical files
+// are written identically.
+//
+// Batches are sorted by their header (excluding the batch number) and entries are sorted
+// by trace number. Addenda05, Addenda17 and Addenda18 records are sorted by sequence number.
+// Batches are then renumbered and each batch and the File are re-created to recompute controls.
+// ADV entries keep their order as they have no trace numbers.
+func (f *File) Canonicalize() error {
+	for _, b := range f.Batches {
+		entries := b.GetEntries()
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].TraceNumberField() < entries[j].TraceNumberField()
+		})
+		for _, ed := range entries {
+			sort.SliceStable(ed.Addenda05, func(i, j int) bool {
+				return ed.Addenda05[i].SequenceNumber < ed.Addenda05[j].SequenceNumber
+			})
+		}
+	}
+	sort.SliceStable(f.Batches, func(i, j int) bool {
+		return batchSortKey(f.Batches[i]) < batchSortKey(f.Batches[j])
+	})
+
+	for i := range f.IATBatches {
+		entries := f.IATBatches[i].Entries
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].TraceNumberField() < entries[j].TraceNumberField()
+		})
+		for _, ed := range entries {
+			sort.SliceStable(ed.Addenda17, func(i, j int) bool {
+				return ed.Addenda17[i].SequenceNumber < ed.Addenda17[j].SequenceNumber
+			})
+			sort.SliceStable(ed.Addenda18, func(i, j int) bool {
+				return ed.Addenda18[i].SequenceNumber < ed.Addenda18[j].SequenceNumber
+			})
+		}
+	}
+	sort.SliceStable(f.IATBatches, func(i, j int) bool {
+		return iatBatchSortKey(&f.IATBatches[i]) < iatBatchSortKey(&f.IATBatches[j])
+	})
+
+	// Clear batch numbers so File.Create assigns them in the new order
+	for _, b := range f.Batches {
+		setBatchNumber(b, 0)
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("canonicalize batch: %w", err)
+		}
+	}
+	for i := range f.IATBatches {
+		f.IATBatches[i].Header.BatchNumber = 0
+		if err := f.IATBatches[i].Create(); err != nil {
+			return fmt.Errorf("canonicalize IAT batch: %w", err)
+		}
+	}
+	return f.Create()
+}
+
+// batchSortKey is a batch's header excluding the batch number, followed by its entry records
+// so batches with matching headers still sort deterministically.
+func batchSortKey(b Batcher) string {
+	var buf strings.Builder
+	buf.WriteString(b.GetHeader().String()[:87])
+	for _, ed := range b.GetEntries() {
+		buf.WriteString(ed.String())
+	}
+	for _, ed := range b.GetADVEntries() {
+		buf.WriteString(ed.String())
+	}
+	return buf.String()
+}
+
+func iatBatchSortKey(b *IATBatch) string {
+	var buf strings.Builder
+	buf.WriteString(b.Header.String()[:87])
+	for _, ed := range b.Entries {
+		buf.WriteString(ed.String())
+	}
+	return buf.String()
+}
+
+// Fingerprint returns a hex encoded SHA-256 hash of a File's contents which can be used for
+// idempotency checks.
+//
+// The FileCreationDate, FileCreationTime and FileIDModifier, batch numbers and control records are
+// ignored, as is the order of batches, entries and addenda records. Two files which only differ in
+// those fields have the same Fingerprint. The File is not modified.
+func (f *File) Fingerprint() string {
+	header := f.Header
+	header.FileCreationDate = ""
+	header.FileCreationTime = ""
+	header.FileIDModifier = ""
+
+	var batches []string
+	for _, b := range f.Batches {
+		var blocks []string
+		for _, ed := range b.GetEntries() {
+			blocks = append(blocks, entryFingerprintBlock(ed))
+		}
+		for _, ed := range b.GetADVEntries() {
+			blocks = append(blocks, ed.String()+writeLines(ed.Addenda99))
+		}
+		batches = append(batches, fingerprintBatch(b.GetHeader().String()[:87], blocks))
+	}
+	for i := range f.IATBatches {
+		var blocks []string
+		for _, ed := range f.IATBatches[i].Entries {
+			blocks = append(blocks, iatEntryFingerprintBlock(ed))
+		}
+		batches = append(batches, fingerprintBatch(f.IATBatches[i].Header.String()[:87], blocks))
+	}
+	sort.Strings(batches)
+
+	h := sha256.New()
+	h.Write([]byte(header.String() + "\n"))
+	for i := range batches {
+		h.Write([]byte(batches[i]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fingerprintBatch(header string, blocks []string) string {
+	sort.Strings(blocks)
+	return header + "\n" + strings.Join(blocks, "")
+}
+
+func entryFingerprintBlock(ed *EntryDetail) string {
+	addenda05 := make([]string, 0, len(ed.Addenda05))
+	for _, a := range ed.Addenda05 {
+		addenda05 = append(addenda05, a.String()+"\n")
+	}
+	sort.Strings(addenda05)
+
+	return writeLines(ed, ed.Addenda02) + strings.Join(addenda05, "") +
+		writeLines(ed.Addenda98, ed.Addenda98Refused, ed.Addenda99, ed.Addenda99Dishonored, ed.Addenda99Contested)
+}
+
+func iatEntryFingerprintBlock(ed *IATEntryDetail) string {
+	var optional []string
+	for _, a := range ed.Addenda17 {
+		optional = append(optional, a.String()+"\n")
+	}
+	for _, a := range ed.Addenda18 {
+		optional = append(optional, a.String()+"\n")
+	}
+	sort.Strings(optional)
+
+	return writeLines(ed, ed.Addenda10, ed.Addenda11, ed.Addenda12, ed.Addenda13, ed.Addenda14, ed.Addenda15, ed.Addenda16) +
+		strings.Join(optional, "") + writeLines(ed.Addenda98, ed.Addenda99)
+}
+
+// writeLines returns each record's String() on its own line, skipping records which are nil or empty.
+func writeLines(records ...writeEntry) string {
+	var buf strings.Builder
+	for _, r := range records {
+		if r == nil {
+			continue
+		}
+		if line := r.String(); line != "" {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,125 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFileBytes(t *testing.T, file *File) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf).Write(file))
+	return buf.Bytes()
+}
+
+func shuffledTestFile(t *testing.T, path string) *File {
+	t.Helper()
+
+	file, err := readACHFilepath(path)
+	require.NoError(t, err)
+
+	for i, j := 0, len(file.Batches)-1; i < j; i, j = i+1, j-1 {
+		file.Batches[i], file.Batches[j] = file.Batches[j], file.Batches[i]
+	}
+	for _, b := range file.Batches {
+		entries := b.GetEntries()
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	for i, j := 0, len(file.IATBatches)-1; i < j; i, j = i+1, j-1 {
+		file.IATBatches[i], file.IATBatches[j] = file.IATBatches[j], file.IATBatches[i]
+	}
+	return file
+}
+
+func TestFile__Canonicalize(t *testing.T) {
+	paths := []string{
+		filepath.Join("test", "testdata", "web-debit.ach"),
+		filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"),
+		filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"),
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			original, err := readACHFilepath(path)
+			require.NoError(t, err)
+			require.NoError(t, original.Canonicalize())
+			require.NoError(t, original.Validate())
+
+			shuffled := shuffledTestFile(t, path)
+			require.NoError(t, shuffled.Canonicalize())
+
+			require.Equal(t, string(writeFileBytes(t, original)), string(writeFileBytes(t, shuffled)))
+
+			// canonicalizing twice is a no-op
+			require.NoError(t, shuffled.Canonicalize())
+			require.Equal(t, string(writeFileBytes(t, original)), string(writeFileBytes(t, shuffled)))
+		})
+	}
+}
+
+func TestFile__CanonicalizeOrder(t *testing.T) {
+	file := shuffledTestFile(t, filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, file.Canonicalize())
+
+	for i, b := range file.Batches {
+		require.Equal(t, i+1, b.GetHeader().BatchNumber)
+		require.Equal(t, i+1, b.GetControl().BatchNumber)
+		if i > 0 {
+			require.LessOrEqual(t, batchSortKey(file.Batches[i-1]), batchSortKey(b))
+		}
+		entries := b.GetEntries()
+		for j := 1; j < len(entries); j++ {
+			require.Less(t, entries[j-1].TraceNumber, entries[j].TraceNumber)
+		}
+	}
+}
+
+func TestFile__Fingerprint(t *testing.T) {
+	path := filepath.Join("test", "testdata", "web-debit.ach")
+
+	original, err := readACHFilepath(path)
+	require.NoError(t, err)
+	fingerprint := original.Fingerprint()
+	require.Len(t, fingerprint, 64)
+
+	// order, batch numbers and file creation fields are ignored
+	shuffled := shuffledTestFile(t, path)
+	require.Equal(t, fingerprint, shuffled.Fingerprint())
+
+	require.NoError(t, shuffled.Canonicalize())
+	shuffled.Header.FileCreationDate = "991231"
+	shuffled.Header.FileCreationTime = "2359"
+	shuffled.Header.FileIDModifier = "Z"
+	require.Equal(t, fingerprint, shuffled.Fingerprint())
+
+	// content changes are not
+	shuffled.Batches[0].GetEntries()[0].Amount += 1
+	require.NotEqual(t, fingerprint, shuffled.Fingerprint())
+
+	iat, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+	require.NotEqual(t, fingerprint, iat.Fingerprint())
+	require.Equal(t, iat.Fingerprint(), shuffledTestFile(t, filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach")).Fingerprint())
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -13,6 +14,11 @@ import (
 )
 
 func dumpFiles(paths []string, validateOpts *ach.ValidateOpts) error {
+	var dir *ach.FedACHDirectory
+	if validateOpts != nil {
+		dir = validateOpts.FedACHDirectory
+	}
+
 	files := make([]*ach.File, len(paths))
 	for i := range paths {
 		f, err := readACHFile(paths[i], validateOpts)
@@ -21,11 +27,14 @@ func dumpFiles(paths []string, validateOpts *ach.ValidateOpts) error {
 		}
 		if f != nil {
 			files[i] = f
+			if err := dir.ValidateFile(f); err != nil && !validateOpts.SkipAll {
+				fmt.Printf("WARN: %s: %v\n\n", paths[i], err)
+			}
 		}
 	}
 
 	if *flagMerge {
-		merged, err := ach.MergeFiles(files)
+		merged, err := mergeFiles(paths, files)
 		if err != nil {
 			fmt.Printf("ERROR: merging files: %v\n", err)
 		}
@@ -58,6 +67,7 @@ func dumpFiles(paths []string, validateOpts *ach.ValidateOpts) error {
 				MaskCorrectedData:  *flagMask || *flagMaskCorrectedData,
 				MaskNames:          *flagMask || *flagMaskNames,
 				PrettyAmounts:      *flagPretty || *flagPrettyAmounts,
+				FedACHDirectory:    dir,
 			})
 		} else {
 			fmt.Printf("nil ACH file in position %d\n", i)
@@ -67,6 +77,34 @@ func dumpFiles(paths []string, validateOpts *ach.ValidateOpts) error {
 	return nil
 }
 
+func mergeFiles(paths []string, files []*ach.File) ([]*ach.File, error) {
+	if *flagMergeProvenance == "" {
+		return ach.MergeFiles(files)
+	}
+
+	// Identify each input file by its path in the provenance
+	for i := range files {
+		if files[i] != nil && files[i].ID == "" {
+			files[i].ID = paths[i]
+		}
+	}
+	merged, provenance, err := ach.MergeFilesWithProvenance(files, ach.Conditions{
+		MaxLines: ach.NACHAFileLineLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bs, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("problem encoding merge provenance: %v", err)
+	}
+	if err := os.WriteFile(*flagMergeProvenance, bs, 0600); err != nil {
+		return nil, fmt.Errorf("problem writing merge provenance: %v", err)
+	}
+	return merged, nil
+}
+
 func readACHFile(path string, validateOpts *ach.ValidateOpts) (*ach.File, error) {
 	fd, readErr := os.Open(path)
 	if readErr != nil {
@@ -25,6 +25,9 @@ type Opts struct {
 	MaskCorrectedData  bool
 
 	PrettyAmounts bool
+
+	// FedACHDirectory is used to look up the names of RDFIs and the ImmediateDestination
+	FedACHDirectory *ach.FedACHDirectory
 }
 
 func File(ww io.Writer, file *ach.File, opts *Opts) {
@@ -41,8 +44,8 @@ func File(ww io.Writer, file *ach.File, opts *Opts) {
 	fh, fc := file.Header, file.Control
 
 	// FileHeader
-	fmt.Fprintln(w, "  Origin\tOriginName\tDestination\tDestinationName\tFileCreationDate\tFileCreationTime")
-	fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", fh.ImmediateOriginField(), fh.ImmediateOriginNameField(), fh.ImmediateDestinationField(), fh.ImmediateDestinationNameField(), fh.FileCreationDateField(), fh.FileCreationTimeField())
+	fmt.Fprintln(w, "  Origin\tOriginName\tDestination\tDestinationName\tFileCreationDate\tFileCreationTime"+bankNameHeader(opts, "DestinationBank"))
+	fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s%s\n", fh.ImmediateOriginField(), fh.ImmediateOriginNameField(), fh.ImmediateDestinationField(), fh.ImmediateDestinationNameField(), fh.FileCreationDateField(), fh.FileCreationTimeField(), bankName(opts, fh.ImmediateDestination))
 
 	// Batches
 	for i := range file.Batches {
@@ -66,7 +69,7 @@ func File(ww io.Writer, file *ach.File, opts *Opts) {
 
 		entries := file.Batches[i].GetEntries()
 		for j := range entries {
-			fmt.Fprintln(w, "\n    TransactionCode\tRDFIIdentification\tAccountNumber\tAmount\tName\tTraceNumber\tCategory")
+			fmt.Fprintln(w, "\n    TransactionCode\tRDFIIdentification\tAccountNumber\tAmount\tName\tTraceNumber\tCategory"+bankNameHeader(opts, "RDFIName"))
 
 			e := entries[j]
 			accountNumber := e.DFIAccountNumberField()
@@ -81,7 +84,7 @@ func File(ww io.Writer, file *ach.File, opts *Opts) {
 				name = maskName(name)
 			}
 
-			fmt.Fprintf(w, "    %d %s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.TransactionCode, transactionCodes[e.TransactionCode], e.RDFIIdentificationField(), accountNumber, amount, name, e.TraceNumberField(), e.Category)
+			fmt.Fprintf(w, "    %d %s\t%s\t%s\t%s\t%s\t%s\t%s%s\n", e.TransactionCode, transactionCodes[e.TransactionCode], e.RDFIIdentificationField(), accountNumber, amount, name, e.TraceNumberField(), e.Category, bankName(opts, e.RDFIIdentification))
 
 			dumpAddenda02(w, e.Addenda02)
 			for i := range e.Addenda05 {
@@ -139,7 +142,7 @@ func File(ww io.Writer, file *ach.File, opts *Opts) {
 
 		entries := iatBatch.GetEntries()
 		for j := range entries {
-			fmt.Fprintln(w, "\n    TransactionCode\tRDFIIdentification\tAccountNumber\tAmount\tAddendaRecords\tTraceNumber\tCategory")
+			fmt.Fprintln(w, "\n    TransactionCode\tRDFIIdentification\tAccountNumber\tAmount\tAddendaRecords\tTraceNumber\tCategory"+bankNameHeader(opts, "RDFIName"))
 
 			e := entries[j]
 			accountNumber := e.DFIAccountNumberField()
@@ -148,7 +151,7 @@ func File(ww io.Writer, file *ach.File, opts *Opts) {
 			}
 
 			amount := formatAmount(opts.PrettyAmounts, e.Amount)
-			fmt.Fprintf(w, "    %d %s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.TransactionCode, transactionCodes[e.TransactionCode], e.RDFIIdentificationField(), accountNumber, amount, e.AddendaRecordsField(), e.TraceNumberField(), e.Category)
+			fmt.Fprintf(w, "    %d %s\t%s\t%s\t%s\t%s\t%s\t%s%s\n", e.TransactionCode, transactionCodes[e.TransactionCode], e.RDFIIdentificationField(), accountNumber, amount, e.AddendaRecordsField(), e.TraceNumberField(), e.Category, bankName(opts, e.RDFIIdentification))
 
 			dumpAddenda10(w, e.Addenda10)
 			dumpAddenda11(w, e.Addenda11)
@@ -357,6 +360,25 @@ func dumpAddenda18(w *tabwriter.Writer, a *ach.Addenda18) {
 		a.ForeignCorrespondentBankBranchCountryCodeField(), a.SequenceNumberField(), a.EntryDetailSequenceNumberField())
 }
 
+// bankNameHeader returns an extra column header when bank names are looked up
+func bankNameHeader(opts *Opts, column string) string {
+	if opts.FedACHDirectory == nil {
+		return ""
+	}
+	return "\t" + column
+}
+
+// bankName returns a column with the FedACH participant's name for the routing number
+func bankName(opts *Opts, routingNumber string) string {
+	if opts.FedACHDirectory == nil {
+		return ""
+	}
+	if p, ok := opts.FedACHDirectory.Lookup(routingNumber); ok {
+		return "\t" + p.CustomerName
+	}
+	return "\tUnknown"
+}
+
 func maskNumber(s string) string {
 	length := utf8.RuneCountInString(s)
 	if length < 5 {
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/moov-io/ach"
@@ -109,3 +110,19 @@ func TestMaskName(t *testing.T) {
 	}
 	require.Equal(t, "Ja** Sm*** **", maskName(ed.IndividualNameField()))
 }
+
+func TestDescribeFedACHDirectory(t *testing.T) {
+	file, err := ach.ReadFile(filepath.Join("..", "..", "..", "test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+	dir, err := ach.ReadFedACHDirectoryFile(filepath.Join("..", "..", "..", "test", "testdata", "fedachdir.txt"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	File(&buf, file, &Opts{FedACHDirectory: dir})
+	if testing.Verbose() {
+		os.Stdout.Write(buf.Bytes())
+	}
+	require.Contains(t, buf.String(), "DestinationBank")
+	require.Contains(t, buf.String(), "RDFIName")
+	require.Equal(t, 2, strings.Count(buf.String(), "CITADEL FEDERAL CREDIT UNION"))
+}
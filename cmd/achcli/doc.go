@@ -21,8 +21,12 @@ USAGE
 
 EXAMPLES
   achcli -diff first.ach second.ach    Show the difference between two ACH files
+  achcli -fedach FedACHdir.txt file.ach
+                                       Validate routing numbers against the FedACH directory and show bank names
+  achcli -generate=PPD                 Write a synthetic ACH file with PPD entries to stdout
   achcli -mask file.ach                Print file details with personally identifiable information partially removed
   achcli -reformat=json first.ach      Convert an incoming ACH file into another format (options: ach, json)
+  achcli -stats *.ach                  Print totals of all entries grouped by SEC code, RDFI, company and more
   achcli -validate opts.json file.ach  Read an ACH File with the provided ValidateOpts
   achcli -version                      Print the version of achcli (Example: %s)
   achcli 20060102.ach                  Summarize an ACH file for human readability
@@ -0,0 +1,28 @@
+// Copyright 2026 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+
+	"github.com/moov-io/ach"
+)
+
+func generate(sec string) error {
+	file, err := ach.GenerateFile(ach.GenerateOpts{
+		StandardEntryClassCode: sec,
+		Category:               *flagGenerateCategory,
+		Batches:                *flagGenerateBatches,
+		EntriesPerBatch:        *flagGenerateEntries,
+		MinAmount:              *flagGenerateMinAmount,
+		MaxAmount:              *flagGenerateMaxAmount,
+		Distribution:           ach.AmountDistribution(*flagGenerateAmounts),
+		Seed:                   *flagGenerateSeed,
+	})
+	if err != nil {
+		return err
+	}
+	return ach.NewWriter(os.Stdout).Write(file)
+}
@@ -18,10 +18,21 @@ var (
 	flagVerbose = flag.Bool("v", false, "Print verbose details about each ACH file")
 	flagVersion = flag.Bool("version", false, "Print moov-io/ach cli version")
 
-	flagDiff     = flag.Bool("diff", false, "Compare two files against each other")
-	flagFlatten  = flag.Bool("flatten", false, "Flatten batches in each file")
-	flagMerge    = flag.Bool("merge", false, "Merge files before describing")
-	flagReformat = flag.String("reformat", "", "Reformat an incoming ACH file to another format")
+	flagDiff            = flag.Bool("diff", false, "Compare two files against each other")
+	flagFedACH          = flag.String("fedach", "", "Path to a FedACH directory file to validate routing numbers and describe bank names")
+	flagFlatten         = flag.Bool("flatten", false, "Flatten batches in each file")
+	flagMerge           = flag.Bool("merge", false, "Merge files before describing")
+	flagMergeProvenance = flag.String("merge.provenance", "", "Path to write a JSON mapping of each input entry to its merged file")
+	flagReformat        = flag.String("reformat", "", "Reformat an incoming ACH file to another format")
+
+	flagGenerate          = flag.String("generate", "", "Write a synthetic ACH file of the given SEC code (e.g. PPD, IAT) to stdout")
+	flagGenerateAmounts   = flag.String("generate.amounts", "uniform", "Distribution of generated amounts (options: uniform, normal, lognormal)")
+	flagGenerateBatches   = flag.Int("generate.batches", 1, "Number of batches to generate")
+	flagGenerateCategory  = flag.String("generate.category", "Forward", "Category of generated entries (options: Forward, Return, NOC)")
+	flagGenerateEntries   = flag.Int("generate.entries", 10, "Number of entries to generate in each batch")
+	flagGenerateMaxAmount = flag.Int("generate.max", 1000000, "Maximum generated amount in cents")
+	flagGenerateMinAmount = flag.Int("generate.min", 100, "Minimum generated amount in cents")
+	flagGenerateSeed      = flag.Int64("generate.seed", 0, "Seed for generating a reproducible file")
 
 	flagMask              = flag.Bool("mask", false, "Mask/hide full account numbers and individual names")
 	flagMaskAccounts      = flag.Bool("mask.accounts", false, "Mask/hide full account numbers")
@@ -31,6 +42,8 @@ var (
 	flagPretty        = flag.Bool("pretty", false, "Display all values in their human readable format")
 	flagPrettyAmounts = flag.Bool("pretty.amounts", false, "Display human readable amounts instead of exact values")
 
+	flagStats = flag.Bool("stats", false, "Print JSON totals of the entries in all files grouped by SEC code, RDFI, company and more")
+
 	flagSkipValidation = flag.Bool("skip-validation", false, "Skip all validation checks")
 	flagValidateOpts   = flag.String("validate", "", "Path to config file in json format to enable validation opts")
 )
@@ -56,6 +69,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *flagGenerate != "" {
+		if err := generate(*flagGenerate); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// minor debugging
 	if *flagVerbose {
 		fmt.Printf("found %d ACH files to describe: %s\n", len(args), strings.Join(args, ", "))
@@ -69,6 +90,17 @@ func main() {
 
 	// Read validation options from the command
 	validateOpts := readValidationOpts(*flagValidateOpts)
+	if *flagFedACH != "" {
+		dir, err := ach.ReadFedACHDirectoryFile(*flagFedACH)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		if validateOpts == nil {
+			validateOpts = &ach.ValidateOpts{SkipAll: *flagSkipValidation}
+		}
+		validateOpts.FedACHDirectory = dir
+	}
 
 	// pick our command to do
 	switch {
@@ -78,6 +110,12 @@ func main() {
 			os.Exit(1)
 		}
 
+	case *flagStats:
+		if err := stats(args, validateOpts); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+
 	case *flagReformat != "" && len(args) == 1:
 		if err := reformat(*flagReformat, args[0], validateOpts); err != nil {
 			fmt.Printf("ERROR: %v\n", err)
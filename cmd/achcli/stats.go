@@ -0,0 +1,31 @@
+// Copyright 2026 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/moov-io/ach"
+)
+
+func stats(paths []string, validateOpts *ach.ValidateOpts) error {
+	files := make([]*ach.File, len(paths))
+	for i := range paths {
+		file, err := readIncomingFile(paths[i], validateOpts)
+		if err != nil {
+			return err
+		}
+		files[i] = file
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ach.Summarize(files...)); err != nil {
+		return fmt.Errorf("problem encoding summary: %v", err)
+	}
+	return nil
+}
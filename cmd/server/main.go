@@ -20,6 +20,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
@@ -75,6 +76,15 @@ func main() {
 	}
 	r := server.NewRepositoryInMemory(achFileTTL, logger)
 	svc = server.NewService(r)
+	if path := os.Getenv("ACH_EXPOSURE_LIMITS"); path != "" {
+		limits, err := readExposureLimits(path)
+		if err != nil {
+			logger.Fatal().LogErrorf("problem reading exposure limits: %v", err)
+			os.Exit(1)
+		}
+		logger.Logf("Checking files against exposure limits from %s", path)
+		svc = server.NewServiceWithLimits(r, limits)
+	}
 
 	// Create HTTP server
 	handler = server.MakeHTTPHandler(svc, r, kitlog.With(kitlogger, "component", "HTTP"))
@@ -160,3 +170,15 @@ func main() {
 		logger.LogError(err)
 	}
 }
+
+func readExposureLimits(path string) (*ach.ExposureLimits, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var limits ach.ExposureLimits
+	if err := json.Unmarshal(bs, &limits); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
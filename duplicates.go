@@ -0,0 +1,349 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SubmittedFile is what a DuplicateDetector remembers about a File.
+type SubmittedFile struct {
+	// Fingerprint is the File's Fingerprint, which ignores creation times, batch numbers and control records
+	Fingerprint          string `json:"fingerprint"`
+	FileID               string `json:"fileID,omitempty"`
+	ImmediateOrigin      string `json:"immediateOrigin"`
+	ImmediateDestination string `json:"immediateDestination"`
+	FileCreationDate     string `json:"fileCreationDate"`
+	FileIDModifier       string `json:"fileIDModifier"`
+}
+
+// SubmittedEntry is what a DuplicateDetector remembers about an entry.
+type SubmittedEntry struct {
+	TraceNumber        string `json:"traceNumber"`
+	TransactionCode    int    `json:"transactionCode"`
+	Amount             int    `json:"amount"`
+	RDFIIdentification string `json:"RDFIIdentification"`
+	DFIAccountNumber   string `json:"DFIAccountNumber"`
+	EffectiveEntryDate string `json:"effectiveEntryDate"`
+	FileID             string `json:"fileID,omitempty"`
+}
+
+// DuplicateEntry is an entry which looks like a previously submitted entry.
+type DuplicateEntry struct {
+	Entry    SubmittedEntry `json:"entry"`
+	Original SubmittedEntry `json:"original"`
+	// SameTraceNumber is true when the entries also share a trace number, which makes
+	// them an exact duplicate rather than a likely one.
+	SameTraceNumber bool `json:"sameTraceNumber"`
+}
+
+// DuplicateReport is the result of DuplicateDetector.Check.
+type DuplicateReport struct {
+	// File is the previously submitted file with the same fingerprint
+	File *SubmittedFile `json:"file,omitempty"`
+	// FileIDModifier is the previously submitted file with the same FileIDModifier,
+	// ImmediateOrigin, ImmediateDestination and FileCreationDate
+	FileIDModifier *SubmittedFile `json:"fileIDModifier,omitempty"`
+	// Entries are the entries which match a previously submitted entry
+	Entries []DuplicateEntry `json:"entries,omitempty"`
+}
+
+// Empty returns true when no duplicates were found.
+func (r *DuplicateReport) Empty() bool {
+	return r == nil || (r.File == nil && r.FileIDModifier == nil && len(r.Entries) == 0)
+}
+
+// duplicateEntryKey is how entries are matched. Trace numbers are often reassigned when a
+// file is rebuilt, so they're compared after a match rather than being part of the key.
+type duplicateEntryKey struct {
+	rdfi, account, date string
+	transactionCode     int
+	amount              int
+}
+
+func (e *SubmittedEntry) key() duplicateEntryKey {
+	return duplicateEntryKey{
+		rdfi:            e.RDFIIdentification,
+		account:         strings.TrimSpace(e.DFIAccountNumber),
+		date:            e.EffectiveEntryDate,
+		transactionCode: e.TransactionCode,
+		amount:          e.Amount,
+	}
+}
+
+// DuplicateDetector flags files and entries which were already submitted.
+//
+// Files are remembered with Add and compared with Check. The index can be written with WriteIndex
+// and read back later with ReadIndex so it survives restarts. A DuplicateDetector is safe for
+// concurrent use.
+type DuplicateDetector struct {
+	mu      sync.RWMutex
+	files   map[string]*SubmittedFile
+	entries map[duplicateEntryKey][]*SubmittedEntry
+}
+
+// NewDuplicateDetector returns a DuplicateDetector with an empty index.
+func NewDuplicateDetector() *DuplicateDetector {
+	return &DuplicateDetector{
+		files:   make(map[string]*SubmittedFile),
+		entries: make(map[duplicateEntryKey][]*SubmittedEntry),
+	}
+}
+
+// Len returns how many files are indexed.
+func (d *DuplicateDetector) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.files)
+}
+
+// Check compares file against the indexed files and entries. It returns an exact duplicate file,
+// a file reusing the FileIDModifier for the same day, origin and destination, and entries with the
+// same account, transaction code, amount and EffectiveEntryDate as an indexed entry.
+func (d *DuplicateDetector) Check(file *File) (*DuplicateReport, error) {
+	submitted, err := submittedFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	report := &DuplicateReport{}
+	if original, exists := d.files[submitted.Fingerprint]; exists {
+		copied := *original
+		report.File = &copied
+	}
+	for _, original := range d.files {
+		if original.Fingerprint != submitted.Fingerprint && original.FileIDModifier == submitted.FileIDModifier &&
+			original.FileCreationDate == submitted.FileCreationDate &&
+			original.ImmediateOrigin == submitted.ImmediateOrigin &&
+			original.ImmediateDestination == submitted.ImmediateDestination {
+			copied := *original
+			report.FileIDModifier = &copied
+			break
+		}
+	}
+	for _, ed := range submittedEntries(file) {
+		originals := d.entries[ed.key()]
+		if len(originals) == 0 {
+			continue
+		}
+		// prefer an original with the same trace number
+		original := originals[0]
+		for _, o := range originals {
+			if o.TraceNumber == ed.TraceNumber {
+				original = o
+				break
+			}
+		}
+		report.Entries = append(report.Entries, DuplicateEntry{
+			Entry:           *ed,
+			Original:        *original,
+			SameTraceNumber: original.TraceNumber == ed.TraceNumber,
+		})
+	}
+	return report, nil
+}
+
+// Add indexes file and its entries. Adding a file with the same fingerprint again replaces it.
+func (d *DuplicateDetector) Add(file *File) error {
+	submitted, err := submittedFile(file)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.files[submitted.Fingerprint]; exists {
+		return nil
+	}
+	d.files[submitted.Fingerprint] = submitted
+	for _, ed := range submittedEntries(file) {
+		d.addEntry(ed)
+	}
+	return nil
+}
+
+// Prune removes files created before the given date and entries with an EffectiveEntryDate before
+// it, returning how many files were removed. Dates which don't parse are kept.
+func (d *DuplicateDetector) Prune(before time.Time) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cutoff := before.Format("060102")
+	expired := func(date string) bool {
+		if _, err := time.Parse("060102", date); err != nil {
+			return false
+		}
+		return date < cutoff
+	}
+
+	var removed int
+	for fingerprint, f := range d.files {
+		if expired(f.FileCreationDate) {
+			delete(d.files, fingerprint)
+			removed++
+		}
+	}
+	for key := range d.entries {
+		if expired(key.date) {
+			delete(d.entries, key)
+		}
+	}
+	return removed
+}
+
+// duplicateIndex is the persisted format of a DuplicateDetector's index
+type duplicateIndex struct {
+	Files   []*SubmittedFile  `json:"files"`
+	Entries []*SubmittedEntry `json:"entries"`
+}
+
+// WriteIndex writes the indexed files and entries to w as JSON, sorted so an index is always written the same way.
+func (d *DuplicateDetector) WriteIndex(w io.Writer) error {
+	d.mu.RLock()
+	var index duplicateIndex
+	for _, f := range d.files {
+		index.Files = append(index.Files, f)
+	}
+	for _, entries := range d.entries {
+		index.Entries = append(index.Entries, entries...)
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(index.Files, func(i, j int) bool {
+		return index.Files[i].Fingerprint < index.Files[j].Fingerprint
+	})
+	sort.Slice(index.Entries, func(i, j int) bool {
+		a, b := index.Entries[i], index.Entries[j]
+		switch {
+		case a.TraceNumber != b.TraceNumber:
+			return a.TraceNumber < b.TraceNumber
+		case a.EffectiveEntryDate != b.EffectiveEntryDate:
+			return a.EffectiveEntryDate < b.EffectiveEntryDate
+		case a.TransactionCode != b.TransactionCode:
+			return a.TransactionCode < b.TransactionCode
+		case a.Amount != b.Amount:
+			return a.Amount < b.Amount
+		case a.RDFIIdentification != b.RDFIIdentification:
+			return a.RDFIIdentification < b.RDFIIdentification
+		case a.DFIAccountNumber != b.DFIAccountNumber:
+			return a.DFIAccountNumber < b.DFIAccountNumber
+		}
+		return a.FileID < b.FileID
+	})
+	if err := json.NewEncoder(w).Encode(index); err != nil {
+		return fmt.Errorf("writing duplicate index: %w", err)
+	}
+	return nil
+}
+
+// ReadIndex adds the files and entries written by WriteIndex to the DuplicateDetector's index.
+func (d *DuplicateDetector) ReadIndex(r io.Reader) error {
+	var index duplicateIndex
+	if err := json.NewDecoder(r).Decode(&index); err != nil {
+		return fmt.Errorf("reading duplicate index: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range index.Files {
+		if f != nil && f.Fingerprint != "" {
+			d.files[f.Fingerprint] = f
+		}
+	}
+	for _, ed := range index.Entries {
+		if ed != nil {
+			d.addEntry(ed)
+		}
+	}
+	return nil
+}
+
+// addEntry indexes ed unless an identical entry is already indexed, so reading
+// the same index twice doesn't double it.
+func (d *DuplicateDetector) addEntry(ed *SubmittedEntry) {
+	key := ed.key()
+	for _, existing := range d.entries[key] {
+		if *existing == *ed {
+			return
+		}
+	}
+	d.entries[key] = append(d.entries[key], ed)
+}
+
+// submittedFile uses the File's Fingerprint so a file resubmitted with a new creation time,
+// FileIDModifier or batch numbers is still an exact duplicate.
+func submittedFile(file *File) (*SubmittedFile, error) {
+	if file == nil {
+		return nil, errors.New("duplicates: nil File")
+	}
+	return &SubmittedFile{
+		Fingerprint:          file.Fingerprint(),
+		FileID:               file.ID,
+		ImmediateOrigin:      file.Header.ImmediateOrigin,
+		ImmediateDestination: file.Header.ImmediateDestination,
+		FileCreationDate:     file.Header.FileCreationDate,
+		FileIDModifier:       file.Header.FileIDModifier,
+	}, nil
+}
+
+func submittedEntries(file *File) []*SubmittedEntry {
+	var out []*SubmittedEntry
+	for _, b := range file.Batches {
+		bh := b.GetHeader()
+		for _, ed := range b.GetEntries() {
+			if ed.Offset {
+				continue
+			}
+			out = append(out, &SubmittedEntry{
+				TraceNumber:        ed.TraceNumberField(),
+				TransactionCode:    ed.TransactionCode,
+				Amount:             ed.Amount,
+				RDFIIdentification: ed.RDFIIdentification,
+				DFIAccountNumber:   ed.DFIAccountNumber,
+				EffectiveEntryDate: bh.EffectiveEntryDate,
+				FileID:             file.ID,
+			})
+		}
+	}
+	for i := range file.IATBatches {
+		bh := file.IATBatches[i].GetHeader()
+		for _, ed := range file.IATBatches[i].Entries {
+			out = append(out, &SubmittedEntry{
+				TraceNumber:        ed.TraceNumberField(),
+				TransactionCode:    ed.TransactionCode,
+				Amount:             ed.Amount,
+				RDFIIdentification: ed.RDFIIdentification,
+				DFIAccountNumber:   ed.DFIAccountNumber,
+				EffectiveEntryDate: bh.EffectiveEntryDate,
+				FileID:             file.ID,
+			})
+		}
+	}
+	return out
+}
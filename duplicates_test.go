@@ -0,0 +1,143 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateDetector(t *testing.T) {
+	readFile := func() *File {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+		require.NoError(t, err)
+		return file
+	}
+	original := readFile()
+	original.ID = "original"
+	entries := len(original.Batches[0].GetEntries()) + len(original.Batches[1].GetEntries()) + len(original.Batches[2].GetEntries())
+
+	d := NewDuplicateDetector()
+	report, err := d.Check(original)
+	require.NoError(t, err)
+	require.True(t, report.Empty())
+	require.NoError(t, d.Add(original))
+	require.NoError(t, d.Add(original))
+	require.Equal(t, 1, d.Len())
+
+	// resubmitted with a new creation time and FileIDModifier
+	resubmitted := readFile()
+	resubmitted.Header.FileCreationTime = "2359"
+	resubmitted.Header.FileIDModifier = "B"
+	report, err = d.Check(resubmitted)
+	require.NoError(t, err)
+	require.NotNil(t, report.File)
+	require.Equal(t, "original", report.File.FileID)
+	require.Nil(t, report.FileIDModifier)
+	require.Len(t, report.Entries, entries)
+	require.True(t, report.Entries[0].SameTraceNumber)
+
+	// resequenced with new batch numbers and batches in another order
+	resequenced := readFile()
+	resequenced.Batches[0], resequenced.Batches[2] = resequenced.Batches[2], resequenced.Batches[0]
+	for i, b := range resequenced.Batches {
+		b.GetHeader().BatchNumber = i + 10
+		b.GetControl().BatchNumber = i + 10
+	}
+	require.NoError(t, resequenced.Create())
+	report, err = d.Check(resequenced)
+	require.NoError(t, err)
+	require.NotNil(t, report.File)
+	require.Equal(t, "original", report.File.FileID)
+
+	// a different file reusing the FileIDModifier with one rebuilt entry
+	reused := readFile()
+	ed := reused.Batches[0].GetEntries()[0]
+	ed.TraceNumber = "231380100000099"
+	reused.Batches[1].GetEntries()[0].Amount++
+	report, err = d.Check(reused)
+	require.NoError(t, err)
+	require.Nil(t, report.File)
+	require.NotNil(t, report.FileIDModifier)
+	require.Equal(t, original.Header.FileIDModifier, report.FileIDModifier.FileIDModifier)
+	require.Len(t, report.Entries, entries-1)
+
+	rebuilt := report.Entries[0]
+	require.Equal(t, ed.TraceNumber, rebuilt.Entry.TraceNumber)
+	require.Equal(t, original.Batches[0].GetEntries()[0].TraceNumber, rebuilt.Original.TraceNumber)
+	require.False(t, rebuilt.SameTraceNumber)
+	require.Equal(t, "original", rebuilt.Original.FileID)
+
+	_, err = d.Check(nil)
+	require.Error(t, err)
+}
+
+func TestDuplicateDetector__Index(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	iat, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+
+	d := NewDuplicateDetector()
+	require.NoError(t, d.Add(file))
+	require.NoError(t, d.Add(iat))
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteIndex(&buf))
+
+	read := NewDuplicateDetector()
+	require.NoError(t, read.ReadIndex(&buf))
+	require.Equal(t, 2, read.Len())
+
+	// reading the same index again doesn't duplicate entries
+	var written bytes.Buffer
+	require.NoError(t, read.WriteIndex(&written))
+	require.NoError(t, read.ReadIndex(bytes.NewReader(written.Bytes())))
+	require.Equal(t, 2, read.Len())
+	var reread bytes.Buffer
+	require.NoError(t, read.WriteIndex(&reread))
+	require.Equal(t, written.String(), reread.String())
+
+	report, err := read.Check(iat)
+	require.NoError(t, err)
+	require.NotNil(t, report.File)
+	require.Len(t, report.Entries, len(iat.IATBatches[0].Entries)+len(iat.IATBatches[1].Entries))
+
+	require.Error(t, read.ReadIndex(bytes.NewReader([]byte("{"))))
+}
+
+func TestDuplicateDetector__Prune(t *testing.T) {
+	file := mockFilePPD(t)
+	file.Header.FileCreationDate = "260101"
+	file.Batches[0].GetHeader().EffectiveEntryDate = "260102"
+
+	d := NewDuplicateDetector()
+	require.NoError(t, d.Add(file))
+	require.Zero(t, d.Prune(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)))
+
+	require.Equal(t, 1, d.Prune(time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)))
+	require.Zero(t, d.Len())
+
+	report, err := d.Check(file)
+	require.NoError(t, err)
+	require.True(t, report.Empty())
+}
@@ -97,6 +97,9 @@ type EntryDetail struct {
 	Addenda99Dishonored *Addenda99Dishonored `json:"addenda99Dishonored,omitempty"`
 	// Category defines if the entry is a Forward, Return, or NOC
 	Category string `json:"category,omitempty"`
+	// Offset is true when the entry is a balancing offset record created from a Batch's Offset.
+	// Offset records are removed and re-tabulated on each Batch.Create call.
+	Offset bool `json:"offset,omitempty"`
 	// validator is composed for data validation
 	validator
 	// converters is composed for ACH to golang Converters
@@ -657,10 +660,15 @@ func (ed *EntryDetail) TraceNumberField() string {
 
 // CreditOrDebit returns a "C" for credit or "D" for debit based on the entry TransactionCode
 func (ed *EntryDetail) CreditOrDebit() string {
-	if ed.TransactionCode < 10 || ed.TransactionCode > 99 {
+	return creditOrDebit(ed.TransactionCode)
+}
+
+// creditOrDebit returns a "C" for credit or "D" for debit based on a TransactionCode
+func creditOrDebit(code int) string {
+	if code < 10 || code > 99 {
 		return ""
 	}
-	tc := strconv.Itoa(ed.TransactionCode)
+	tc := strconv.Itoa(code)
 
 	// take the second number in the TransactionCode
 	switch tc[1:2] {
@@ -0,0 +1,204 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FedACHParticipant is a record of the Federal Reserve's FedACH participant directory.
+type FedACHParticipant struct {
+	// RoutingNumber is the institution's 9 digit routing number
+	RoutingNumber string `json:"routingNumber"`
+	// OfficeCode is O for a main office and B for a branch
+	OfficeCode string `json:"officeCode"`
+	// ServicingFRBNumber is the routing number of the Federal Reserve Bank servicing the institution
+	ServicingFRBNumber string `json:"servicingFRBNumber"`
+	// RecordTypeCode is 0 for a Federal Reserve Bank, 1 when entries are sent to RoutingNumber and
+	// 2 when entries are sent to NewRoutingNumber
+	RecordTypeCode string `json:"recordTypeCode"`
+	// ChangeDate is the MMDDYY date the record last changed
+	ChangeDate       string `json:"changeDate"`
+	NewRoutingNumber string `json:"newRoutingNumber"`
+	CustomerName     string `json:"customerName"`
+	Address          string `json:"address"`
+	City             string `json:"city"`
+	State            string `json:"state"`
+	PostalCode       string `json:"postalCode"`
+	PhoneNumber      string `json:"phoneNumber"`
+	// StatusCode is the institution's status, 1 when it receives Government and Commercial entries
+	StatusCode string `json:"statusCode"`
+	ViewCode   string `json:"viewCode"`
+}
+
+// FedACHDirectory is an index of the institutions eligible to receive ACH entries. It's read from the
+// fixed-width FedACH directory text file published by the Federal Reserve's E-Payments Routing Directory.
+//
+// Set a FedACHDirectory on ValidateOpts to require every RDFIIdentification and ImmediateDestination
+// to be a participant.
+type FedACHDirectory struct {
+	participants map[string]*FedACHParticipant
+	// aba8 indexes participants by their routing number without a check digit
+	aba8 map[string]*FedACHParticipant
+}
+
+// fedACHLineLength is the length of each record in the FedACH directory file
+const fedACHLineLength = 155
+
+// ReadFedACHDirectoryFile reads the FedACH directory file at path.
+func ReadFedACHDirectoryFile(path string) (*FedACHDirectory, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading FedACH directory: %w", err)
+	}
+	defer fd.Close()
+
+	return ReadFedACHDirectory(fd)
+}
+
+// ReadFedACHDirectory reads the records of a FedACH directory file. Records are 155 characters;
+// trailing filler may be trimmed.
+func ReadFedACHDirectory(r io.Reader) (*FedACHDirectory, error) {
+	dir := &FedACHDirectory{
+		participants: make(map[string]*FedACHParticipant),
+		aba8:         make(map[string]*FedACHParticipant),
+	}
+
+	scanner := bufio.NewScanner(r)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseFedACHParticipant(line)
+		if err != nil {
+			return nil, fmt.Errorf("FedACH directory line %d: %w", lineNum, err)
+		}
+		dir.participants[p.RoutingNumber] = p
+		dir.aba8[p.RoutingNumber[:8]] = p
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading FedACH directory: %w", err)
+	}
+	return dir, nil
+}
+
+func parseFedACHParticipant(line string) (*FedACHParticipant, error) {
+	// fields after the new routing number may be trimmed
+	if n := len(line); n < 35 || n > fedACHLineLength {
+		return nil, fmt.Errorf("invalid record length of %d", n)
+	}
+	line += strings.Repeat(" ", fedACHLineLength-len(line))
+
+	field := func(start, end int) string {
+		return strings.TrimSpace(line[start:end])
+	}
+	p := &FedACHParticipant{
+		RoutingNumber:      field(0, 9),
+		OfficeCode:         field(9, 10),
+		ServicingFRBNumber: field(10, 19),
+		RecordTypeCode:     field(19, 20),
+		ChangeDate:         field(20, 26),
+		NewRoutingNumber:   field(26, 35),
+		CustomerName:       field(35, 71),
+		Address:            field(71, 107),
+		City:               field(107, 127),
+		State:              field(127, 129),
+		PostalCode:         field(129, 134),
+		PhoneNumber:        field(138, 148),
+		StatusCode:         field(148, 149),
+		ViewCode:           field(149, 150),
+	}
+	if ext := field(134, 138); ext != "" {
+		p.PostalCode += "-" + ext
+	}
+	if err := CheckRoutingNumber(p.RoutingNumber); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Len returns how many participants are in the directory.
+func (d *FedACHDirectory) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.participants)
+}
+
+// Lookup returns the participant with a routing number. Both 9 digit routing numbers and the 8 digit
+// RDFIIdentification of entries are accepted.
+func (d *FedACHDirectory) Lookup(routingNumber string) (*FedACHParticipant, bool) {
+	if d == nil {
+		return nil, false
+	}
+	routingNumber = strings.TrimSpace(routingNumber)
+	var p *FedACHParticipant
+	switch len(routingNumber) {
+	case 8:
+		p = d.aba8[routingNumber]
+	case 9:
+		p = d.participants[routingNumber]
+	}
+	return p, p != nil
+}
+
+// ValidateFile returns an ErrFileUnknownParticipant when the File's ImmediateDestination or any
+// RDFIIdentification isn't in the directory.
+func (d *FedACHDirectory) ValidateFile(f *File) error {
+	return d.validate(f, &ValidateOpts{})
+}
+
+// validate returns an ErrFileUnknownParticipant for the first ImmediateDestination
+// or RDFIIdentification which isn't in the directory
+func (d *FedACHDirectory) validate(f *File, opts *ValidateOpts) error {
+	if d == nil {
+		return nil
+	}
+	if !opts.BypassDestinationValidation {
+		if _, ok := d.Lookup(f.Header.ImmediateDestination); !ok {
+			return NewErrFileUnknownParticipant("ImmediateDestination", f.Header.ImmediateDestination)
+		}
+	}
+	for _, b := range f.Batches {
+		for _, ed := range b.GetEntries() {
+			if _, ok := d.Lookup(ed.RDFIIdentification); !ok {
+				return NewErrFileUnknownParticipant("RDFIIdentification", ed.RDFIIdentification)
+			}
+		}
+		for _, ed := range b.GetADVEntries() {
+			if _, ok := d.Lookup(ed.RDFIIdentification); !ok {
+				return NewErrFileUnknownParticipant("RDFIIdentification", ed.RDFIIdentification)
+			}
+		}
+	}
+	for i := range f.IATBatches {
+		for _, ed := range f.IATBatches[i].Entries {
+			if _, ok := d.Lookup(ed.RDFIIdentification); !ok {
+				return NewErrFileUnknownParticipant("RDFIIdentification", ed.RDFIIdentification)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFedACHDirectory(t *testing.T) {
+	dir, err := ReadFedACHDirectoryFile(filepath.Join("test", "testdata", "fedachdir.txt"))
+	require.NoError(t, err)
+	require.Equal(t, 4, dir.Len())
+
+	p, ok := dir.Lookup("121042882")
+	require.True(t, ok)
+	require.Equal(t, "WELLS FARGO BANK NA", p.CustomerName)
+	require.Equal(t, "O", p.OfficeCode)
+	require.Equal(t, "121000374", p.ServicingFRBNumber)
+	require.Equal(t, "1", p.RecordTypeCode)
+	require.Equal(t, "MINNEAPOLIS", p.City)
+	require.Equal(t, "MN", p.State)
+	require.Equal(t, "55479-0000", p.PostalCode)
+	require.Equal(t, "8004452995", p.PhoneNumber)
+	require.Equal(t, "1", p.StatusCode)
+
+	// RDFIIdentification doesn't include the check digit
+	p, ok = dir.Lookup("02100002")
+	require.True(t, ok)
+	require.Equal(t, "021000089", p.NewRoutingNumber)
+
+	_, ok = dir.Lookup(" 231380104")
+	require.True(t, ok)
+	_, ok = dir.Lookup("091000019")
+	require.False(t, ok)
+
+	var empty *FedACHDirectory
+	_, ok = empty.Lookup("121042882")
+	require.False(t, ok)
+	require.Zero(t, empty.Len())
+}
+
+func TestFedACHDirectory__Read(t *testing.T) {
+	// trailing filler can be trimmed
+	dir, err := ReadFedACHDirectory(strings.NewReader("121042882O1210003741050115000000000WELLS FARGO BANK NA\r\n\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1, dir.Len())
+
+	_, err = ReadFedACHDirectory(strings.NewReader("121042882O121000374"))
+	require.ErrorContains(t, err, "line 1: invalid record length")
+
+	_, err = ReadFedACHDirectory(strings.NewReader("121042881O1210003741050115000000000WELLS FARGO BANK NA"))
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = ReadFedACHDirectoryFile(filepath.Join("test", "testdata", "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestFile__ValidateFedACHDirectory(t *testing.T) {
+	dir, err := ReadFedACHDirectoryFile(filepath.Join("test", "testdata", "fedachdir.txt"))
+	require.NoError(t, err)
+
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+	opts := &ValidateOpts{FedACHDirectory: dir}
+	require.NoError(t, file.ValidateWith(opts))
+
+	file.Batches[0].GetEntries()[0].SetRDFI("091000019")
+	require.NoError(t, file.Batches[0].Create())
+	require.NoError(t, file.Create())
+	err = file.ValidateWith(opts)
+	var unknown ErrFileUnknownParticipant
+	require.True(t, errors.As(err, &unknown))
+	require.Equal(t, "RDFIIdentification", unknown.Field)
+	require.Equal(t, "09100001", unknown.RoutingNumber)
+
+	file.Batches[0].GetEntries()[0].SetRDFI("231380104")
+	require.NoError(t, file.Batches[0].Create())
+	require.NoError(t, file.Create())
+	file.Header.ImmediateDestination = "091000019"
+	require.ErrorContains(t, file.ValidateWith(opts), "ImmediateDestination 091000019 is not a FedACH participant")
+
+	opts.BypassDestinationValidation = true
+	require.NoError(t, file.ValidateWith(opts))
+}
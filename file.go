@@ -704,6 +704,16 @@ type ValidateOpts struct {
 
 	// AllowInvalidAmounts will skip verifying the Amount is valid for the TransactionCode and entry type.
 	AllowInvalidAmounts bool `json:"allowInvalidAmounts"`
+
+	// ExposureLimits are daily credit and debit limits per originator and SEC code which the File's
+	// entries are checked against. An ExposureLimitError is returned when they're exceeded.
+	ExposureLimits *ExposureLimits `json:"exposureLimits,omitempty"`
+
+	// FedACHDirectory requires the ImmediateDestination and every RDFIIdentification to be
+	// an ACH participant in the directory.
+	//
+	// Note: The directory is read with ReadFedACHDirectoryFile and cannot be set from config files.
+	FedACHDirectory *FedACHDirectory `json:"-"`
 }
 
 // merge will combine two ValidateOpts structs and keep any non-zero field values.
@@ -742,6 +752,15 @@ func (v *ValidateOpts) merge(other *ValidateOpts) *ValidateOpts {
 		out.CheckTransactionCode = other.CheckTransactionCode
 	}
 
+	out.ExposureLimits = v.ExposureLimits
+	if other.ExposureLimits != nil {
+		out.ExposureLimits = other.ExposureLimits
+	}
+	out.FedACHDirectory = v.FedACHDirectory
+	if other.FedACHDirectory != nil {
+		out.FedACHDirectory = other.FedACHDirectory
+	}
+
 	return out
 }
 
@@ -796,7 +815,13 @@ func (f *File) ValidateWith(opts *ValidateOpts) error {
 				return err
 			}
 		}
-		return f.isEntryHash(false)
+		if err := f.isEntryHash(false); err != nil {
+			return err
+		}
+		if err := opts.FedACHDirectory.validate(f, opts); err != nil {
+			return err
+		}
+		return opts.ExposureLimits.validate(f)
 	}
 
 	// File contains ADV batches BatchADV
@@ -816,7 +841,10 @@ func (f *File) ValidateWith(opts *ValidateOpts) error {
 	if err := f.isFileAmount(true); err != nil {
 		return err
 	}
-	return f.isEntryHash(true)
+	if err := f.isEntryHash(true); err != nil {
+		return err
+	}
+	return opts.FedACHDirectory.validate(f, opts)
 }
 
 // isEntryAddendaCount is prepared by hashing the RDFI's 8-digit Routing Number in each entry.
@@ -1021,7 +1049,12 @@ func (f *File) createFileADV() error {
 //
 // The File returned may not be valid and callers should confirm with Validate. Invalid files may be rejected
 // by other Financial Institutions or ACH tools.
-func (f *File) SegmentFile(_ *SegmentFileConfiguration) (*File, *File, error) {
+//
+// SegmentFile only separates credits and debits. Use SegmentFileBy for the other SegmentFileConfiguration options.
+func (f *File) SegmentFile(sfc *SegmentFileConfiguration) (*File, *File, error) {
+	if !sfc.segmentsByCreditsAndDebits() {
+		return nil, nil, ErrSegmentFileConfiguration
+	}
 	if err := f.Validate(); err != nil {
 		return nil, nil, err
 	}
@@ -1067,6 +1100,137 @@ func (f *File) SegmentFile(_ *SegmentFileConfiguration) (*File, *File, error) {
 	return creditFile, debitFile, nil
 }
 
+// SegmentFileBy takes a valid ACH File and returns segmented ACH Files keyed by what each File contains.
+// Entries are always segmented into credits and debits, and further segmented according to the
+// SegmentFileConfiguration (SEC code, service class, company identification, RDFI, prenotes and
+// zero dollar remittances).
+//
+// Callers should always check for a nil-error before using the returned files.
+func (f *File) SegmentFileBy(sfc *SegmentFileConfiguration) (map[SegmentKey]*File, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[SegmentKey]*File)
+	fileFor := func(key SegmentKey) *File {
+		file, exists := files[key]
+		if !exists {
+			file = f.addFileHeaderData(NewFile())
+			if f.validateOpts != nil {
+				file.SetValidation(f.validateOpts)
+			}
+			files[key] = file
+		}
+		return file
+	}
+
+	type batchKey struct {
+		segment SegmentKey
+		index   int
+	}
+
+	// Segment each Batch into new batches for each SegmentKey, keeping batches in their original order
+	batches := make(map[batchKey]Batcher)
+	var batchOrder []batchKey
+	for i, batch := range f.Batches {
+		bh := batch.GetHeader()
+		for _, entry := range batch.GetEntries() {
+			key := sfc.segmentKey(entry.CreditOrDebit(), bh.StandardEntryClassCode, bh.ServiceClassCode,
+				bh.CompanyIdentification, entry.RDFIIdentification, entry.TransactionCode)
+
+			bk := batchKey{segment: key, index: i}
+			b, exists := batches[bk]
+			if !exists {
+				serviceClassCode := CreditsOnly
+				if key.CreditOrDebit == "D" {
+					serviceClassCode = DebitsOnly
+				}
+				nb, err := NewBatch(createSegmentFileBatchHeader(serviceClassCode, bh))
+				if err != nil {
+					return nil, err
+				}
+				b = nb
+				batches[bk] = b
+				batchOrder = append(batchOrder, bk)
+			}
+			b.AddEntry(entry)
+		}
+		for _, entry := range batch.GetADVEntries() {
+			var direction string
+			switch entry.TransactionCode {
+			case CreditForDebitsOriginated, CreditForCreditsReceived, CreditForCreditsRejected, CreditSummary:
+				direction = "C"
+			case DebitForCreditsOriginated, DebitForDebitsReceived, DebitForDebitsRejectedBatches, DebitSummary:
+				direction = "D"
+			}
+			key := sfc.segmentKey(direction, bh.StandardEntryClassCode, bh.ServiceClassCode,
+				bh.CompanyIdentification, entry.RDFIIdentification, entry.TransactionCode)
+
+			bk := batchKey{segment: key, index: i}
+			b, exists := batches[bk]
+			if !exists {
+				nb, err := NewBatch(createSegmentFileBatchHeader(AutomatedAccountingAdvices, bh))
+				if err != nil {
+					return nil, err
+				}
+				b = nb
+				batches[bk] = b
+				batchOrder = append(batchOrder, bk)
+			}
+			b.AddADVEntry(entry)
+		}
+	}
+	for _, bk := range batchOrder {
+		b := batches[bk]
+		if err := b.Create(); err != nil {
+			return nil, err
+		}
+		fileFor(bk.segment).AddBatch(b)
+	}
+
+	// Segment each IATBatch the same way
+	iatBatches := make(map[batchKey]*IATBatch)
+	var iatBatchOrder []batchKey
+	for i := range f.IATBatches {
+		bh := f.IATBatches[i].GetHeader()
+		for _, entry := range f.IATBatches[i].GetEntries() {
+			key := sfc.segmentKey(entry.CreditOrDebit(), bh.StandardEntryClassCode, bh.ServiceClassCode,
+				bh.OriginatorIdentification, entry.RDFIIdentification, entry.TransactionCode)
+
+			bk := batchKey{segment: key, index: i}
+			b, exists := iatBatches[bk]
+			if !exists {
+				serviceClassCode := CreditsOnly
+				if key.CreditOrDebit == "D" {
+					serviceClassCode = DebitsOnly
+				}
+				nb := NewIATBatch(createSegmentFileIATBatchHeader(serviceClassCode, bh))
+				b = &nb
+				iatBatches[bk] = b
+				iatBatchOrder = append(iatBatchOrder, bk)
+			}
+			b.AddEntry(entry)
+		}
+	}
+	for _, bk := range iatBatchOrder {
+		b := iatBatches[bk]
+		if err := b.Create(); err != nil {
+			return nil, err
+		}
+		fileFor(bk.segment).AddIATBatch(*b)
+	}
+
+	for key := range files {
+		if err := files[key].Create(); err != nil {
+			return nil, err
+		}
+		if err := files[key].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
 func (f *File) segmentFileBatches(creditFile, debitFile *File) error {
 	for _, batch := range f.Batches {
 		bh := batch.GetHeader()
@@ -1209,14 +1373,19 @@ func createSegmentFileIATBatchHeader(serviceClassCode int, IATBh *IATBatchHeader
 	nbh := NewIATBatchHeader()
 	nbh.ID = base.ID()
 	nbh.ServiceClassCode = serviceClassCode
+	nbh.IATIndicator = IATBh.IATIndicator
 	nbh.ForeignExchangeIndicator = IATBh.ForeignExchangeIndicator
 	nbh.ForeignExchangeReferenceIndicator = IATBh.ForeignExchangeReferenceIndicator
+	nbh.ForeignExchangeReference = IATBh.ForeignExchangeReference
 	nbh.ISODestinationCountryCode = IATBh.ISODestinationCountryCode
 	nbh.OriginatorIdentification = IATBh.OriginatorIdentification
 	nbh.StandardEntryClassCode = IATBh.StandardEntryClassCode
 	nbh.CompanyEntryDescription = IATBh.CompanyEntryDescription
 	nbh.ISOOriginatingCurrencyCode = IATBh.ISOOriginatingCurrencyCode
 	nbh.ISODestinationCurrencyCode = IATBh.ISODestinationCurrencyCode
+	nbh.EffectiveEntryDate = IATBh.EffectiveEntryDate
+	nbh.SettlementDate = IATBh.SettlementDate
+	nbh.OriginatorStatusCode = IATBh.OriginatorStatusCode
 	nbh.ODFIIdentification = IATBh.ODFIIdentification
 	return nbh
 }
@@ -45,6 +45,14 @@ var (
 	ErrFileIATSEC = errors.New("IAT Standard Entry Class Code should use iatBatch")
 	// ErrFileNoBatches is the error given if a file has no batches
 	ErrFileNoBatches = errors.New("must have []*Batches or []*IATBatches to be built")
+	// ErrSegmentFileConfiguration is the error given when SegmentFile is asked to segment beyond credits and debits
+	ErrSegmentFileConfiguration = errors.New("SegmentFile only segments credits and debits, use SegmentFileBy")
+	// ErrFileResequenceOverflow is the error given when Resequence runs past the seven digit batch or trace sequence
+	ErrFileResequenceOverflow = errors.New("resequence exceeds the maximum batch or trace sequence number")
+	// ErrExposureLimitExceeded is the error given when a file's entries exceed an originator or SEC code's daily limit
+	ErrExposureLimitExceeded = errors.New("exposure limit exceeded")
+	// ErrInvalidMICR is the error given when a check's MICR line can't be read
+	ErrInvalidMICR = errors.New("invalid MICR line")
 
 	ErrInvalidJSON = errors.New("invalid JSON")
 )
@@ -144,3 +152,23 @@ func NewErrFileBatchNumberAscending(previous, current int) ErrFileBatchNumberAsc
 func (e ErrFileBatchNumberAscending) Error() string {
 	return e.Message
 }
+
+// ErrFileUnknownParticipant is the error given when a routing number isn't an ACH participant in the FedACH directory
+type ErrFileUnknownParticipant struct {
+	Message       string
+	Field         string
+	RoutingNumber string
+}
+
+// NewErrFileUnknownParticipant creates a new error of the ErrFileUnknownParticipant type
+func NewErrFileUnknownParticipant(field, routingNumber string) ErrFileUnknownParticipant {
+	return ErrFileUnknownParticipant{
+		Message:       fmt.Sprintf("%s %s is not a FedACH participant", field, routingNumber),
+		Field:         field,
+		RoutingNumber: routingNumber,
+	}
+}
+
+func (e ErrFileUnknownParticipant) Error() string {
+	return e.Message
+}
@@ -1424,6 +1424,108 @@ func TestFile__SegmentADVFile(t *testing.T) {
 	}
 }
 
+func TestFile__SegmentFileBy(t *testing.T) {
+	achFile, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+
+	t.Run("credits and debits", func(t *testing.T) {
+		files, err := achFile.SegmentFileBy(nil)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		credits := files[SegmentKey{CreditOrDebit: "C"}]
+		require.NotNil(t, credits)
+		require.Equal(t, 2, countTraceNumbers(credits))
+		require.Equal(t, CreditsOnly, credits.Batches[0].GetHeader().ServiceClassCode)
+
+		debits := files[SegmentKey{CreditOrDebit: "D"}]
+		require.NotNil(t, debits)
+		require.Equal(t, 1, countTraceNumbers(debits))
+		require.Equal(t, DebitsOnly, debits.Batches[0].GetHeader().ServiceClassCode)
+	})
+
+	t.Run("ByRDFI and ByServiceClassCode", func(t *testing.T) {
+		files, err := achFile.SegmentFileBy(&SegmentFileConfiguration{
+			ByRDFI:             true,
+			ByServiceClassCode: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		key := SegmentKey{
+			CreditOrDebit:      "C",
+			ServiceClassCode:   MixedDebitsAndCredits,
+			RDFIIdentification: "23138010",
+		}
+		require.NotNil(t, files[key])
+		require.NoError(t, files[key].Validate())
+	})
+
+	t.Run("SeparatePrenotes", func(t *testing.T) {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+		require.NoError(t, err)
+
+		entry := file.Batches[0].GetEntries()[1]
+		entry.TransactionCode = CheckingPrenoteCredit
+		entry.Amount = 0
+		require.NoError(t, file.Batches[0].Create())
+		require.NoError(t, file.Create())
+
+		files, err := file.SegmentFileBy(&SegmentFileConfiguration{
+			BySECCode:        true,
+			SeparatePrenotes: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, files, 3)
+
+		prenotes := files[SegmentKey{CreditOrDebit: "C", StandardEntryClassCode: PPD, Prenote: true}]
+		require.NotNil(t, prenotes)
+		require.Equal(t, 1, countTraceNumbers(prenotes))
+		require.Equal(t, CheckingPrenoteCredit, prenotes.Batches[0].GetEntries()[0].TransactionCode)
+	})
+
+	t.Run("IAT ByCompanyIdentification", func(t *testing.T) {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "iat-mixedDebitCredit.ach"))
+		require.NoError(t, err)
+
+		files, err := file.SegmentFileBy(&SegmentFileConfiguration{
+			ByCompanyIdentification: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		for key, f := range files {
+			require.Equal(t, file.IATBatches[0].Header.OriginatorIdentification, key.CompanyIdentification)
+			require.Len(t, f.IATBatches, 1)
+		}
+	})
+
+	t.Run("IAT NOC", func(t *testing.T) {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "iat-addenda98.ach"))
+		require.NoError(t, err)
+		bh := file.IATBatches[0].GetHeader()
+
+		files, err := file.SegmentFileBy(nil)
+		require.NoError(t, err)
+		for _, f := range files {
+			require.NoError(t, f.Validate())
+			for _, b := range f.IATBatches {
+				nbh := b.GetHeader()
+				require.Equal(t, IATCOR, nbh.IATIndicator)
+				require.Equal(t, bh.EffectiveEntryDate, nbh.EffectiveEntryDate)
+				require.Equal(t, bh.OriginatorStatusCode, nbh.OriginatorStatusCode)
+				require.NotNil(t, b.GetEntries()[0].Addenda98)
+			}
+		}
+	})
+
+	t.Run("SegmentFile requires credits and debits only", func(t *testing.T) {
+		_, _, err := achFile.SegmentFile(&SegmentFileConfiguration{
+			BySECCode: true,
+		})
+		require.ErrorIs(t, err, ErrSegmentFileConfiguration)
+	})
+}
+
 func TestFile__SegmentADVFileDebit(t *testing.T) {
 	bs, err := os.ReadFile(filepath.Join("test", "testdata", "adv-valid.json"))
 	if err != nil {
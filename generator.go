@@ -0,0 +1,746 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AmountDistribution describes how GenerateFile picks entry amounts between GenerateOpts.MinAmount and MaxAmount.
+type AmountDistribution string
+
+const (
+	// AmountUniform picks every amount in the range with equal probability
+	AmountUniform AmountDistribution = "uniform"
+	// AmountNormal clusters amounts around the middle of the range
+	AmountNormal AmountDistribution = "normal"
+	// AmountLogNormal skews amounts towards the low end of the range with a long tail of larger amounts
+	AmountLogNormal AmountDistribution = "lognormal"
+)
+
+// GenerateOpts are the parameters for GenerateFile.
+type GenerateOpts struct {
+	// StandardEntryClassCode of the generated batches, such as PPD or IAT. Defaults to PPD.
+	StandardEntryClassCode string `json:"standardEntryClassCode"`
+	// Category is CategoryForward (the default), CategoryReturn or CategoryNOC. Generating COR
+	// batches always creates Notifications of Change.
+	Category string `json:"category"`
+
+	// Batches is how many batches to generate. Defaults to 1.
+	Batches int `json:"batches"`
+	// EntriesPerBatch is how many entries each batch has. Defaults to 10.
+	EntriesPerBatch int `json:"entriesPerBatch"`
+
+	// MinAmount and MaxAmount bound entry amounts in cents. They default to 1.00 and 10,000.00
+	// and are narrowed to any limits the SEC code has.
+	MinAmount int `json:"minAmount"`
+	MaxAmount int `json:"maxAmount"`
+	// Distribution of amounts within the range. Defaults to AmountUniform.
+	Distribution AmountDistribution `json:"distribution"`
+
+	// Seed makes the generated file reproducible. Files generated with the same
+	// GenerateOpts (including Date) are identical.
+	Seed int64 `json:"seed"`
+	// Date is used for the file creation and effective entry dates. Defaults to now.
+	Date time.Time `json:"date"`
+	// ODFIIdentification is the 8 digit routing number prefix of the originating bank. Defaults to a random routing number.
+	ODFIIdentification string `json:"odfiIdentification"`
+}
+
+// generateSpec describes the batch level rules GenerateFile follows for an SEC code.
+type generateSpec struct {
+	serviceClassCode int
+	description      string
+	maxAmount        int
+	zeroDollar       bool
+}
+
+var generateSpecs = map[string]generateSpec{
+	ACK: {serviceClassCode: CreditsOnly, description: "ACK", zeroDollar: true},
+	ADV: {serviceClassCode: AutomatedAccountingAdvices, description: "ADVICE"},
+	ARC: {serviceClassCode: DebitsOnly, description: "CHECK PMT", maxAmount: 2500000},
+	ATX: {serviceClassCode: CreditsOnly, description: "ATX", zeroDollar: true},
+	BOC: {serviceClassCode: DebitsOnly, description: "CHECK PMT", maxAmount: 2500000},
+	CCD: {serviceClassCode: MixedDebitsAndCredits, description: "VENDOR PAY"},
+	CIE: {serviceClassCode: CreditsOnly, description: "BILL PMT"},
+	COR: {serviceClassCode: MixedDebitsAndCredits, description: "CORRECTION"},
+	CTX: {serviceClassCode: MixedDebitsAndCredits, description: "TRADE PMT"},
+	DNE: {serviceClassCode: CreditsOnly, description: "DEATH", zeroDollar: true},
+	ENR: {serviceClassCode: CreditsOnly, description: "AUTOENROLL", zeroDollar: true},
+	IAT: {serviceClassCode: MixedDebitsAndCredits, description: "TRADEPAYMT"},
+	MTE: {serviceClassCode: DebitsOnly, description: "CASH WITHD"},
+	POP: {serviceClassCode: DebitsOnly, description: "PURCHASE", maxAmount: 2500000},
+	POS: {serviceClassCode: DebitsOnly, description: "PURCHASE"},
+	PPD: {serviceClassCode: MixedDebitsAndCredits, description: "PAYROLL"},
+	RCK: {serviceClassCode: DebitsOnly, description: "REDEPCHECK", maxAmount: 250000},
+	SHR: {serviceClassCode: DebitsOnly, description: "PURCHASE"},
+	TEL: {serviceClassCode: DebitsOnly, description: "PHONE PMT"},
+	TRC: {serviceClassCode: DebitsOnly, description: "CHECKTRUNC"},
+	TRX: {serviceClassCode: DebitsOnly, description: "CHECKTRUNC"},
+	WEB: {serviceClassCode: MixedDebitsAndCredits, description: "ONLINE PMT"},
+	XCK: {serviceClassCode: DebitsOnly, description: "CHECKTRUNC", maxAmount: 250000},
+}
+
+var (
+	generateReturnCodes = []string{"R01", "R02", "R03", "R04", "R07", "R08", "R10", "R16", "R20", "R29"}
+	generateStates      = []string{"CA", "IL", "NY", "PA", "TX", "VA", "WA"}
+	generateCurrencies  = []string{"CAD", "EUR", "GBP", "JPY", "MXN"}
+	generateIATTypes    = []string{"ANN", "BUS", "DEP", "LOA", "MIS", "PEN", "SAL", "TAX"}
+	generateFirstNames  = []string{"Ada", "Carlos", "Grace", "Hana", "James", "Liam", "Maria", "Noah", "Priya", "Sofia", "Wei", "Zoe"}
+	generateLastNames   = []string{"Anderson", "Chen", "Garcia", "Johnson", "Kim", "Lopez", "Miller", "Nguyen", "Patel", "Smith", "Taylor", "Walker"}
+	generateCities      = []string{"Austin", "Boston", "Chicago", "Denver", "Miami", "Phoenix", "Portland", "Seattle"}
+	generateStreets     = []string{"Main Street", "Oak Avenue", "Pine Road", "Maple Drive", "Cedar Lane", "Elm Street", "Park Place"}
+	generateWords       = []string{"payment", "invoice", "order", "account", "services", "monthly", "balance", "transfer", "goods", "rent", "fee", "refund"}
+	generateADVCodes    = []int{
+		CreditForDebitsOriginated, CreditForCreditsReceived, CreditForCreditsRejected, CreditSummary,
+		DebitForCreditsOriginated, DebitForDebitsReceived, DebitForDebitsRejectedBatches, DebitSummary,
+	}
+)
+
+// GenerateFile creates a valid File of synthetic data for load testing and QA.
+//
+// Every SEC code with a batch type is supported, including IAT and ADV, along with the addenda
+// records each one requires. Return files carry Addenda99 records and Notification of Change
+// files (COR, or IAT with CategoryNOC) carry Addenda98 records.
+//
+// All random data is drawn from a source seeded with GenerateOpts.Seed, so GenerateFile is safe to
+// call concurrently and results are reproducible.
+func GenerateFile(opts GenerateOpts) (*File, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		opts: opts,
+		rand: rand.New(rand.NewSource(opts.Seed)), //nolint:gosec
+	}
+
+	if g.opts.ODFIIdentification == "" {
+		g.opts.ODFIIdentification = g.routingNumber()[:8]
+	}
+	return g.file()
+}
+
+func (opts GenerateOpts) withDefaults() (GenerateOpts, error) {
+	opts.StandardEntryClassCode = strings.ToUpper(strings.TrimSpace(opts.StandardEntryClassCode))
+	if opts.StandardEntryClassCode == "" {
+		opts.StandardEntryClassCode = PPD
+	}
+	spec, exists := generateSpecs[opts.StandardEntryClassCode]
+	if !exists {
+		return opts, fmt.Errorf("generate: unsupported SEC code %q", opts.StandardEntryClassCode)
+	}
+
+	switch opts.Category {
+	case "":
+		opts.Category = CategoryForward
+	case CategoryForward:
+	case CategoryReturn:
+		if spec.zeroDollar || opts.StandardEntryClassCode == ADV || opts.StandardEntryClassCode == COR {
+			return opts, fmt.Errorf("generate: returns are not supported for %s", opts.StandardEntryClassCode)
+		}
+	case CategoryNOC:
+		if opts.StandardEntryClassCode != COR && opts.StandardEntryClassCode != IAT {
+			return opts, errors.New("generate: Notifications of Change are generated with COR or IAT")
+		}
+	default:
+		return opts, fmt.Errorf("generate: unsupported category %q", opts.Category)
+	}
+	if opts.StandardEntryClassCode == COR {
+		opts.Category = CategoryNOC
+	}
+
+	if opts.Batches <= 0 {
+		opts.Batches = 1
+	}
+	if opts.EntriesPerBatch <= 0 {
+		opts.EntriesPerBatch = 10
+	}
+	if opts.StandardEntryClassCode == ADV && opts.EntriesPerBatch > advMaxEntriesPerBatch {
+		return opts, fmt.Errorf("generate: ADV batches are limited to %d entries", advMaxEntriesPerBatch)
+	}
+
+	if opts.MinAmount <= 0 {
+		opts.MinAmount = 100
+	}
+	if opts.MaxAmount <= 0 {
+		opts.MaxAmount = 1000000
+	}
+	if spec.maxAmount > 0 && opts.MaxAmount > spec.maxAmount {
+		opts.MaxAmount = spec.maxAmount
+	}
+	if opts.MinAmount > opts.MaxAmount {
+		return opts, fmt.Errorf("generate: minimum amount %d is more than maximum amount %d", opts.MinAmount, opts.MaxAmount)
+	}
+	switch opts.Distribution {
+	case "":
+		opts.Distribution = AmountUniform
+	case AmountUniform, AmountNormal, AmountLogNormal:
+	default:
+		return opts, fmt.Errorf("generate: unknown amount distribution %q", opts.Distribution)
+	}
+
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
+	if opts.ODFIIdentification != "" {
+		opts.ODFIIdentification = aba8(opts.ODFIIdentification)
+		if opts.ODFIIdentification == "" {
+			return opts, errors.New("generate: invalid ODFIIdentification")
+		}
+	}
+	return opts, nil
+}
+
+type generator struct {
+	opts GenerateOpts
+	rand *rand.Rand
+}
+
+func (g *generator) file() (*File, error) {
+	file := NewFile()
+	file.Header = g.fileHeader()
+
+	sec := g.opts.StandardEntryClassCode
+	for i := 0; i < g.opts.Batches; i++ {
+		switch sec {
+		case IAT:
+			batch, err := g.iatBatch()
+			if err != nil {
+				return nil, err
+			}
+			file.AddIATBatch(batch)
+		default:
+			batch, err := g.batch()
+			if err != nil {
+				return nil, err
+			}
+			file.AddBatch(batch)
+		}
+	}
+
+	if err := file.Create(); err != nil {
+		return nil, fmt.Errorf("generate %s: %w", sec, err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("generate %s: %w", sec, err)
+	}
+	return file, nil
+}
+
+func (g *generator) fileHeader() FileHeader {
+	fh := NewFileHeader()
+	fh.ImmediateDestination = g.routingNumber()
+	fh.ImmediateOrigin = g.opts.ODFIIdentification + checkDigit(g.opts.ODFIIdentification)
+	fh.FileCreationDate = g.opts.Date.Format("060102")
+	fh.FileCreationTime = g.opts.Date.Format("1504")
+	fh.FileIDModifier = "A"
+	fh.ImmediateDestinationName = "Federal Reserve Bank"
+	fh.ImmediateOriginName = g.text(g.lastName()+" Bank", 23)
+	return fh
+}
+
+func (g *generator) batchHeader() *BatchHeader {
+	sec := g.opts.StandardEntryClassCode
+	spec := generateSpecs[sec]
+
+	bh := NewBatchHeader()
+	bh.ServiceClassCode = spec.serviceClassCode
+	bh.CompanyName = g.text(g.lastName()+" Inc", 16)
+	bh.CompanyIdentification = "1" + g.digits(9)
+	bh.StandardEntryClassCode = sec
+	bh.CompanyEntryDescription = spec.description
+	bh.EffectiveEntryDate = g.opts.Date.AddDate(0, 0, 1).Format("060102")
+	bh.ODFIIdentification = g.opts.ODFIIdentification
+	switch sec {
+	case ADV:
+		bh.OriginatorStatusCode = 0
+	case DNE:
+		bh.OriginatorStatusCode = 2
+	}
+	return bh
+}
+
+func (g *generator) batch() (Batcher, error) {
+	bh := g.batchHeader()
+	batch, err := NewBatch(bh)
+	if err != nil {
+		return nil, err
+	}
+	for seq := 1; seq <= g.opts.EntriesPerBatch; seq++ {
+		switch {
+		case bh.StandardEntryClassCode == ADV:
+			batch.AddADVEntry(g.advEntry())
+		case g.opts.Category == CategoryNOC:
+			batch.AddEntry(g.nocEntry(bh, seq))
+		case g.opts.Category == CategoryReturn:
+			batch.AddEntry(g.returnEntry(bh, seq))
+		default:
+			batch.AddEntry(g.entry(bh, seq))
+		}
+	}
+	if err := batch.Create(); err != nil {
+		return nil, fmt.Errorf("generate %s batch: %w", bh.StandardEntryClassCode, err)
+	}
+	return batch, nil
+}
+
+// entry creates a forward EntryDetail following the rules of the batch's SEC code.
+func (g *generator) entry(bh *BatchHeader, seq int) *EntryDetail {
+	sec := bh.StandardEntryClassCode
+	spec := generateSpecs[sec]
+
+	ed := NewEntryDetail()
+	ed.TransactionCode = g.transactionCode(spec.serviceClassCode)
+	ed.SetRDFI(g.routingNumber())
+	ed.DFIAccountNumber = g.digits(6 + g.rand.Intn(12))
+	ed.Amount = g.amount()
+	ed.IdentificationNumber = g.digits(9)
+	ed.IndividualName = g.text(g.fullName(), 22)
+	ed.Category = CategoryForward
+	ed.SetTraceNumber(bh.ODFIIdentification, seq)
+
+	if spec.zeroDollar {
+		ed.Amount = 0
+	}
+
+	switch sec {
+	case ACK, ATX:
+		ed.TransactionCode = g.pick(CheckingZeroDollarRemittanceCredit, SavingsZeroDollarRemittanceCredit)
+		ed.SetOriginalTraceNumber(g.routingNumber()[:8] + g.digits(7))
+		if sec == ATX {
+			g.addAddenda05(ed, 1+g.rand.Intn(2))
+			ed.SetCATXAddendaRecords(len(ed.Addenda05))
+			ed.SetCATXReceivingCompany(g.company())
+		} else {
+			g.maybeAddenda05(ed)
+		}
+
+	case ARC, BOC, RCK:
+		ed.SetCheckSerialNumber(g.digits(9))
+
+	case CCD, CIE, PPD:
+		g.maybeAddenda05(ed)
+
+	case CTX:
+		g.addAddenda05(ed, 1+g.rand.Intn(3))
+		ed.SetCATXAddendaRecords(len(ed.Addenda05))
+		ed.SetCATXReceivingCompany(g.company())
+
+	case DNE:
+		ed.TransactionCode = g.pick(CheckingReturnNOCCredit, SavingsReturnNOCCredit)
+		ed.AddendaRecordIndicator = 1
+		ed.AddAddenda05(g.addenda05(fmt.Sprintf(`    DATE OF DEATH*%s*CUSTOMERSSN*%s*AMOUNT*%s\`,
+			g.opts.Date.AddDate(0, 0, -g.rand.Intn(60)).Format("010206"), g.digits(9), fmt.Sprintf("%.2f", float64(g.amount())/100))))
+
+	case ENR:
+		ed.TransactionCode = g.pick(CheckingCredit, SavingsCredit)
+		ed.AddendaRecordIndicator = 1
+		rdfi := g.routingNumber()
+		last, first := g.text(g.lastName(), 15), g.text(g.firstName(), 15)
+		ed.AddAddenda05(g.addenda05(fmt.Sprintf(`%d*%s*%s*%s*%s*%s*%s*%d\`,
+			g.pick(CheckingCredit, SavingsCredit), rdfi[:8], rdfi[8:], g.digits(10), g.digits(9),
+			strings.ToUpper(last), strings.ToUpper(first), g.rand.Intn(2))))
+
+	case MTE, POS, SHR:
+		ed.Addenda02 = g.addenda02(ed)
+		ed.AddendaRecordIndicator = 1
+		switch sec {
+		case MTE:
+			ed.SetOriginalTraceNumber(g.digits(15))
+		case POS:
+			ed.DiscretionaryData = g.pickString("01", "02", "99")
+		case SHR:
+			ed.DiscretionaryData = g.pickString("01", "02", "99")
+			ed.SetSHRCardExpirationDate(fmt.Sprintf("%02d%02d", 1+g.rand.Intn(12), (g.opts.Date.Year()+1+g.rand.Intn(4))%100))
+			ed.SetSHRDocumentReferenceNumber(g.digits(11))
+			ed.SetSHRIndividualCardAccountNumber(g.digits(19))
+		}
+
+	case POP:
+		ed.SetPOPCheckSerialNumber(g.digits(9))
+		ed.SetPOPTerminalCity(strings.ToUpper(g.text(g.city(), 4)))
+		ed.SetPOPTerminalState(g.pickString(generateStates...))
+
+	case TEL, WEB:
+		ed.SetPaymentType(g.pickString("S", "R"))
+		if sec == WEB {
+			g.maybeAddenda05(ed)
+		}
+
+	case TRC, XCK:
+		ed.SetCheckSerialNumber(g.digits(9))
+		ed.SetProcessControlField(g.alphanumeric(6))
+		ed.SetItemResearchNumber(g.digits(16))
+		if sec == TRC {
+			ed.SetItemTypeIndicator("01")
+		}
+
+	case TRX:
+		g.addAddenda05(ed, 1+g.rand.Intn(2))
+		ed.SetCATXAddendaRecords(len(ed.Addenda05))
+		ed.SetCATXReceivingCompany(g.company())
+		ed.SetItemTypeIndicator("01")
+	}
+	return ed
+}
+
+// returnEntry creates an EntryDetail returning a forward entry with an Addenda99 record.
+func (g *generator) returnEntry(bh *BatchHeader, seq int) *EntryDetail {
+	ed := g.entry(bh, seq)
+	ed.Addenda02 = nil
+	ed.Addenda05 = nil
+	ed.AddendaRecordIndicator = 1
+	ed.Category = CategoryReturn
+	switch bh.StandardEntryClassCode {
+	case CTX:
+		ed.SetCATXAddendaRecords(1)
+	case TRX:
+		ed.SetCATXAddendaRecords(0)
+	}
+
+	switch ed.TransactionCode {
+	case CheckingCredit:
+		ed.TransactionCode = CheckingReturnNOCCredit
+	case CheckingDebit:
+		ed.TransactionCode = CheckingReturnNOCDebit
+	case SavingsCredit:
+		ed.TransactionCode = SavingsReturnNOCCredit
+	case SavingsDebit:
+		ed.TransactionCode = SavingsReturnNOCDebit
+		if bh.StandardEntryClassCode == CTX {
+			// CTX batches only accept SavingsReturnNOCDebit for zero dollar entries
+			ed.TransactionCode = CheckingReturnNOCDebit
+		}
+	}
+
+	// Returns go back to the bank which originated the entry
+	originalODFI := g.routingNumber()
+	ed.SetRDFI(originalODFI)
+
+	addenda99 := NewAddenda99()
+	addenda99.ReturnCode = g.pickString(generateReturnCodes...)
+	addenda99.OriginalTrace = originalODFI[:8] + g.digits(7)
+	addenda99.OriginalDFI = originalODFI[:8]
+	ed.Addenda99 = addenda99
+	ed.SetTraceNumber(bh.ODFIIdentification, seq)
+	return ed
+}
+
+// nocEntry creates a COR EntryDetail with an Addenda98 record.
+func (g *generator) nocEntry(bh *BatchHeader, seq int) *EntryDetail {
+	ed := NewEntryDetail()
+	ed.TransactionCode = g.pick(CheckingReturnNOCCredit, CheckingReturnNOCDebit, SavingsReturnNOCCredit, SavingsReturnNOCDebit)
+	originalODFI := g.routingNumber()
+	ed.SetRDFI(originalODFI)
+	ed.DFIAccountNumber = g.digits(6 + g.rand.Intn(12))
+	ed.IdentificationNumber = g.digits(9)
+	ed.IndividualName = g.text(g.fullName(), 22)
+	ed.AddendaRecordIndicator = 1
+	ed.Category = CategoryNOC
+	ed.Addenda98 = g.addenda98(originalODFI)
+	ed.SetTraceNumber(bh.ODFIIdentification, seq)
+	return ed
+}
+
+func (g *generator) addenda98(originalODFI string) *Addenda98 {
+	addenda98 := NewAddenda98()
+	addenda98.ChangeCode = g.pickString("C01", "C02", "C05")
+	switch addenda98.ChangeCode {
+	case "C01":
+		addenda98.CorrectedData = g.digits(6 + g.rand.Intn(12))
+	case "C02":
+		addenda98.CorrectedData = g.routingNumber()
+	case "C05":
+		addenda98.CorrectedData = strconv.Itoa(g.pick(CheckingCredit, CheckingDebit, SavingsCredit, SavingsDebit))
+	}
+	addenda98.OriginalTrace = originalODFI[:8] + g.digits(7)
+	addenda98.OriginalDFI = originalODFI[:8]
+	return addenda98
+}
+
+func (g *generator) advEntry() *ADVEntryDetail {
+	ed := NewADVEntryDetail()
+	ed.TransactionCode = g.pick(generateADVCodes...)
+	ed.SetRDFI(g.routingNumber())
+	ed.DFIAccountNumber = g.digits(6 + g.rand.Intn(9))
+	ed.Amount = g.amount()
+	ed.AdviceRoutingNumber = g.routingNumber()
+	ed.FileIdentification = g.digits(5)
+	ed.IndividualName = g.text(g.fullName(), 22)
+	ed.ACHOperatorRoutingNumber = "01100001"
+	ed.JulianDay = g.opts.Date.YearDay()
+	ed.Category = CategoryForward
+	return ed
+}
+
+func (g *generator) iatBatch() (IATBatch, error) {
+	bh := NewIATBatchHeader()
+	bh.ServiceClassCode = MixedDebitsAndCredits
+	bh.ForeignExchangeIndicator = "FF"
+	bh.ForeignExchangeReferenceIndicator = 3
+	bh.ISODestinationCountryCode = "US"
+	bh.OriginatorIdentification = g.digits(9)
+	bh.StandardEntryClassCode = IAT
+	bh.CompanyEntryDescription = generateSpecs[IAT].description
+	bh.ISOOriginatingCurrencyCode = g.pickString(generateCurrencies...)
+	bh.ISODestinationCurrencyCode = "USD"
+	bh.EffectiveEntryDate = g.opts.Date.AddDate(0, 0, 1).Format("060102")
+	bh.ODFIIdentification = g.opts.ODFIIdentification
+	if g.opts.Category == CategoryNOC {
+		bh.IATIndicator = "IATCOR"
+		bh.StandardEntryClassCode = COR
+	}
+
+	batch := NewIATBatch(bh)
+	for seq := 1; seq <= g.opts.EntriesPerBatch; seq++ {
+		batch.AddEntry(g.iatEntry(bh, seq))
+	}
+	if err := batch.Create(); err != nil {
+		return batch, fmt.Errorf("generate IAT batch: %w", err)
+	}
+	return batch, nil
+}
+
+func (g *generator) iatEntry(bh *IATBatchHeader, seq int) *IATEntryDetail {
+	ed := NewIATEntryDetail()
+	ed.TransactionCode = g.transactionCode(MixedDebitsAndCredits)
+	rdfi := g.routingNumber()
+	ed.SetRDFI(rdfi)
+	ed.DFIAccountNumber = g.digits(6 + g.rand.Intn(12))
+	ed.Amount = g.amount()
+	ed.Category = CategoryForward
+	ed.SetTraceNumber(bh.ODFIIdentification, seq)
+
+	ed.Addenda10 = NewAddenda10()
+	ed.Addenda10.TransactionTypeCode = g.pickString(generateIATTypes...)
+	ed.Addenda10.ForeignPaymentAmount = ed.Amount
+	ed.Addenda10.ForeignTraceNumber = g.digits(12)
+	ed.Addenda10.Name = g.text(g.fullName(), 35)
+
+	ed.Addenda11 = NewAddenda11()
+	ed.Addenda11.OriginatorName = g.text(g.company(), 35)
+	ed.Addenda11.OriginatorStreetAddress = g.text(g.street(), 35)
+
+	ed.Addenda12 = NewAddenda12()
+	ed.Addenda12.OriginatorCityStateProvince = g.text(g.city(), 30) + "*ON\\"
+	ed.Addenda12.OriginatorCountryPostalCode = "CA*" + g.digits(6) + "\\"
+
+	ed.Addenda13 = NewAddenda13()
+	ed.Addenda13.ODFIName = g.text(g.lastName()+" Bank", 35)
+	ed.Addenda13.ODFIIDNumberQualifier = "01"
+	ed.Addenda13.ODFIIdentification = bh.ODFIIdentification + checkDigit(bh.ODFIIdentification)
+	ed.Addenda13.ODFIBranchCountryCode = "US"
+
+	ed.Addenda14 = NewAddenda14()
+	ed.Addenda14.RDFIName = g.text(g.lastName()+" Bank", 35)
+	ed.Addenda14.RDFIIDNumberQualifier = "01"
+	ed.Addenda14.RDFIIdentification = rdfi
+	ed.Addenda14.RDFIBranchCountryCode = "US"
+
+	ed.Addenda15 = NewAddenda15()
+	ed.Addenda15.ReceiverIDNumber = g.digits(15)
+	ed.Addenda15.ReceiverStreetAddress = g.text(g.street(), 35)
+
+	ed.Addenda16 = NewAddenda16()
+	ed.Addenda16.ReceiverCityStateProvince = g.text(g.city(), 30) + "*" + g.pickString(generateStates...) + "\\"
+	ed.Addenda16.ReceiverCountryPostalCode = "US*" + g.digits(5) + "\\"
+	ed.AddendaRecords = 7
+
+	switch g.opts.Category {
+	case CategoryReturn:
+		ed.Category = CategoryReturn
+		addenda99 := NewAddenda99()
+		addenda99.ReturnCode = g.pickString(generateReturnCodes...)
+		addenda99.OriginalTrace = rdfi[:8] + g.digits(7)
+		addenda99.OriginalDFI = rdfi[:8]
+		ed.Addenda99 = addenda99
+		ed.SetTraceNumber(bh.ODFIIdentification, seq)
+
+	case CategoryNOC:
+		ed.Category = CategoryNOC
+		ed.TransactionCode = g.pick(CheckingReturnNOCCredit, SavingsReturnNOCCredit)
+		ed.Amount, ed.Addenda10.ForeignPaymentAmount = 0, 0
+		ed.Addenda98 = g.addenda98(rdfi)
+		ed.SetTraceNumber(bh.ODFIIdentification, seq)
+
+	default:
+		for i := g.rand.Intn(3); i > 0; i-- {
+			addenda17 := NewAddenda17()
+			addenda17.PaymentRelatedInformation = g.text(g.paragraph(), 80)
+			ed.AddAddenda17(addenda17)
+		}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			addenda18 := NewAddenda18()
+			addenda18.ForeignCorrespondentBankName = g.text(g.lastName()+" Bank", 35)
+			addenda18.ForeignCorrespondentBankIDNumberQualifier = "01"
+			addenda18.ForeignCorrespondentBankIDNumber = g.digits(15)
+			addenda18.ForeignCorrespondentBankBranchCountryCode = "CA"
+			ed.AddAddenda18(addenda18)
+		}
+		ed.AddendaRecords += len(ed.Addenda17) + len(ed.Addenda18)
+	}
+	return ed
+}
+
+func (g *generator) addenda02(ed *EntryDetail) *Addenda02 {
+	addenda02 := NewAddenda02()
+	addenda02.TerminalIdentificationCode = g.alphanumeric(6)
+	addenda02.TransactionSerialNumber = g.digits(6)
+	addenda02.TransactionDate = g.opts.Date.Format("0102")
+	addenda02.TerminalLocation = g.text(g.street(), 27)
+	addenda02.TerminalCity = g.text(g.city(), 15)
+	addenda02.TerminalState = g.pickString(generateStates...)
+	addenda02.TraceNumber = ed.TraceNumber
+	return addenda02
+}
+
+func (g *generator) addenda05(info string) *Addenda05 {
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = info
+	return addenda05
+}
+
+func (g *generator) addAddenda05(ed *EntryDetail, n int) {
+	for i := 0; i < n; i++ {
+		ed.AddAddenda05(g.addenda05(fmt.Sprintf("RMR*IV*%s**%.2f\\", g.digits(8), float64(g.amount())/100)))
+	}
+	ed.AddendaRecordIndicator = 1
+}
+
+// maybeAddenda05 adds a single Addenda05 record to some entries
+func (g *generator) maybeAddenda05(ed *EntryDetail) {
+	if g.rand.Intn(4) == 0 {
+		ed.AddAddenda05(g.addenda05(g.text(g.paragraph(), 80)))
+		ed.AddendaRecordIndicator = 1
+	}
+}
+
+// transactionCode picks a checking or savings TransactionCode allowed by the ServiceClassCode
+func (g *generator) transactionCode(serviceClassCode int) int {
+	switch serviceClassCode {
+	case CreditsOnly:
+		return g.pick(CheckingCredit, SavingsCredit)
+	case DebitsOnly:
+		return g.pick(CheckingDebit, SavingsDebit)
+	default:
+		return g.pick(CheckingCredit, SavingsCredit, CheckingDebit, SavingsDebit)
+	}
+}
+
+func (g *generator) amount() int {
+	lo, hi := float64(g.opts.MinAmount), float64(g.opts.MaxAmount)
+	var n float64
+	switch g.opts.Distribution {
+	case AmountNormal:
+		n = (lo+hi)/2 + g.rand.NormFloat64()*(hi-lo)/6
+	case AmountLogNormal:
+		// centered low in the range so most amounts are small
+		mu, sigma := math.Log(lo)+(math.Log(hi)-math.Log(lo))/4, (math.Log(hi)-math.Log(lo))/4
+		n = math.Exp(mu + g.rand.NormFloat64()*sigma)
+	default:
+		n = lo + g.rand.Float64()*(hi-lo+1)
+	}
+	return int(math.Max(lo, math.Min(hi, math.Floor(n))))
+}
+
+// routingNumber returns a random, valid 9 digit ABA routing number
+func (g *generator) routingNumber() string {
+	prefix := fmt.Sprintf("%02d", 1+g.rand.Intn(12)) + g.digits(6)
+	return prefix + checkDigit(prefix)
+}
+
+func (g *generator) company() string {
+	return g.text(g.lastName()+" "+g.pickString("Inc", "LLC", "Corp", "Co"), 16)
+}
+
+func (g *generator) digits(n int) string {
+	var buf strings.Builder
+	for i := 0; i < n; i++ {
+		buf.WriteByte(byte('0' + g.rand.Intn(10)))
+	}
+	return buf.String()
+}
+
+func (g *generator) alphanumeric(n int) string {
+	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var buf strings.Builder
+	for i := 0; i < n; i++ {
+		buf.WriteByte(chars[g.rand.Intn(len(chars))])
+	}
+	return buf.String()
+}
+
+func (g *generator) firstName() string {
+	return g.pickString(generateFirstNames...)
+}
+
+func (g *generator) lastName() string {
+	return g.pickString(generateLastNames...)
+}
+
+func (g *generator) fullName() string {
+	return g.firstName() + " " + g.lastName()
+}
+
+func (g *generator) city() string {
+	return g.pickString(generateCities...)
+}
+
+func (g *generator) street() string {
+	return strconv.Itoa(1+g.rand.Intn(9999)) + " " + g.pickString(generateStreets...)
+}
+
+// paragraph returns a sentence of random words, longer than any addenda's free text field
+func (g *generator) paragraph() string {
+	words := make([]string, 16)
+	for i := range words {
+		words[i] = g.pickString(generateWords...)
+	}
+	return strings.ToUpper(words[0][:1]) + strings.Join(words, " ")[1:] + "."
+}
+
+func (g *generator) pick(options ...int) int {
+	return options[g.rand.Intn(len(options))]
+}
+
+func (g *generator) pickString(options ...string) string {
+	return options[g.rand.Intn(len(options))]
+}
+
+// text trims s to max characters, dropping any characters which aren't valid in Nacha alphanumeric fields.
+func (g *generator) text(s string, max int) string {
+	var buf strings.Builder
+	for _, r := range s {
+		if r < 0x80 && strings.ContainsRune(validAlphaNumericCharacters, r) && r != '*' && r != '\\' {
+			buf.WriteRune(r)
+		}
+	}
+	out := strings.TrimSpace(buf.String())
+	if len(out) > max {
+		out = strings.TrimSpace(out[:max])
+	}
+	return out
+}
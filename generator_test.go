@@ -0,0 +1,156 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFile(t *testing.T) {
+	date := time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC)
+
+	for sec := range generateSpecs {
+		categories := []string{CategoryForward}
+		switch {
+		case sec == IAT:
+			categories = append(categories, CategoryReturn, CategoryNOC)
+		case sec == ADV, sec == COR, generateSpecs[sec].zeroDollar:
+		default:
+			categories = append(categories, CategoryReturn)
+		}
+
+		for _, category := range categories {
+			t.Run(sec+"/"+category, func(t *testing.T) {
+				for seed := int64(1); seed <= 5; seed++ {
+					opts := GenerateOpts{
+						StandardEntryClassCode: sec,
+						Category:               category,
+						Batches:                2,
+						EntriesPerBatch:        5,
+						Seed:                   seed,
+						Date:                   date,
+					}
+					file, err := GenerateFile(opts)
+					require.NoError(t, err, "seed %d", seed)
+					require.NoError(t, file.Validate())
+
+					// the file can be read back
+					r := NewReader(bytes.NewReader(writeFileBytes(t, file)))
+					_, err = r.Read()
+					require.NoError(t, err, "seed %d", seed)
+
+					if sec == IAT {
+						require.Len(t, file.IATBatches, 2)
+						require.Len(t, file.IATBatches[0].Entries, 5)
+					} else {
+						require.Len(t, file.Batches, 2)
+						require.Equal(t, 5, len(file.Batches[1].GetEntries())+len(file.Batches[1].GetADVEntries()))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateFile__Reproducible(t *testing.T) {
+	opts := GenerateOpts{
+		StandardEntryClassCode: CTX,
+		Batches:                3,
+		EntriesPerBatch:        20,
+		Distribution:           AmountLogNormal,
+		Seed:                   42,
+		Date:                   time.Date(2026, time.January, 2, 15, 4, 0, 0, time.UTC),
+	}
+	first, err := GenerateFile(opts)
+	require.NoError(t, err)
+	second, err := GenerateFile(opts)
+	require.NoError(t, err)
+	require.Equal(t, string(writeFileBytes(t, first)), string(writeFileBytes(t, second)))
+
+	opts.Seed = 43
+	other, err := GenerateFile(opts)
+	require.NoError(t, err)
+	require.NotEqual(t, string(writeFileBytes(t, first)), string(writeFileBytes(t, other)))
+
+	// concurrent generation doesn't share a random source
+	opts.Seed = 42
+	var wg sync.WaitGroup
+	files := make([]*File, 4)
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			files[i], _ = GenerateFile(opts)
+		}(i)
+	}
+	wg.Wait()
+	for i := range files {
+		require.NotNil(t, files[i])
+		require.Equal(t, string(writeFileBytes(t, first)), string(writeFileBytes(t, files[i])))
+	}
+}
+
+func TestGenerateFile__Amounts(t *testing.T) {
+	for _, dist := range []AmountDistribution{AmountUniform, AmountNormal, AmountLogNormal} {
+		file, err := GenerateFile(GenerateOpts{
+			StandardEntryClassCode: PPD,
+			EntriesPerBatch:        200,
+			MinAmount:              500,
+			MaxAmount:              2000,
+			Distribution:           dist,
+			Seed:                   7,
+		})
+		require.NoError(t, err)
+		for _, ed := range file.Batches[0].GetEntries() {
+			require.GreaterOrEqual(t, ed.Amount, 500, dist)
+			require.LessOrEqual(t, ed.Amount, 2000, dist)
+		}
+	}
+
+	// SEC code limits narrow the range
+	file, err := GenerateFile(GenerateOpts{StandardEntryClassCode: XCK, MinAmount: 100, MaxAmount: 5000000, Seed: 1})
+	require.NoError(t, err)
+	for _, ed := range file.Batches[0].GetEntries() {
+		require.LessOrEqual(t, ed.Amount, 250000)
+	}
+}
+
+func TestGenerateFile__Errors(t *testing.T) {
+	_, err := GenerateFile(GenerateOpts{StandardEntryClassCode: "ABC"})
+	require.Error(t, err)
+
+	_, err = GenerateFile(GenerateOpts{StandardEntryClassCode: PPD, Category: CategoryNOC})
+	require.Error(t, err)
+
+	_, err = GenerateFile(GenerateOpts{StandardEntryClassCode: ENR, Category: CategoryReturn})
+	require.Error(t, err)
+
+	_, err = GenerateFile(GenerateOpts{MinAmount: 500, MaxAmount: 100})
+	require.Error(t, err)
+
+	_, err = GenerateFile(GenerateOpts{Distribution: "triangle"})
+	require.Error(t, err)
+
+	_, err = GenerateFile(GenerateOpts{ODFIIdentification: "abc"})
+	require.Error(t, err)
+}
@@ -0,0 +1,228 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/moov-io/ach/internal/iso3166"
+	"github.com/moov-io/iso4217"
+)
+
+// IATConversion is the cross-border data a domestic entry lacks to be sent as an IAT entry.
+type IATConversion struct {
+	// ForeignExchangeIndicator is FF, FV or VF and defaults to FF
+	ForeignExchangeIndicator string `json:"foreignExchangeIndicator"`
+	// ForeignExchangeReferenceIndicator is 1 (rate), 2 (reference number) or 3 (space filled) and defaults to 3
+	ForeignExchangeReferenceIndicator int    `json:"foreignExchangeReferenceIndicator"`
+	ForeignExchangeReference          string `json:"foreignExchangeReference"`
+	// ISODestinationCountryCode is the ISO 3166 code of the country the entry is destined for
+	ISODestinationCountryCode string `json:"ISODestinationCountryCode"`
+	// ISOOriginatingCurrencyCode and ISODestinationCurrencyCode are ISO 4217 codes and default to USD
+	ISOOriginatingCurrencyCode string `json:"ISOOriginatingCurrencyCode"`
+	ISODestinationCurrencyCode string `json:"ISODestinationCurrencyCode"`
+
+	// TransactionTypeCode defaults to the SEC code of ARC, BOC, POP, RCK, TEL and WEB entries
+	TransactionTypeCode string `json:"transactionTypeCode"`
+
+	// Originator.Name defaults to the batch's CompanyName
+	Originator IATParty `json:"originator"`
+	// Receiver.Name defaults to the entry's IndividualName and Receiver.IdentificationNumber to its IdentificationNumber
+	Receiver IATParty `json:"receiver"`
+
+	// ODFI and RDFI identifications default to the routing numbers of the domestic entry,
+	// qualified as national clearing system numbers in the US.
+	ODFI           IATBank   `json:"ODFI"`
+	RDFI           IATBank   `json:"RDFI"`
+	Correspondents []IATBank `json:"correspondents,omitempty"`
+
+	// Remittance defaults to the PaymentRelatedInformation of the entry's Addenda05 records.
+	// Entries with more than two Addenda05 records, such as CTX, can't be converted without it.
+	Remittance []string `json:"remittance,omitempty"`
+}
+
+// ConvertToIAT converts a domestic entry and its BatchHeader into an IAT entry and IATBatchHeader, as a
+// gateway operator does for entries that are part of a cross-border payment. The entry's trace number is
+// kept. The IATBatchHeader is validated, including the ISO country and currency codes.
+func ConvertToIAT(bh *BatchHeader, ed *EntryDetail, conv IATConversion) (*IATBatchHeader, *IATEntryDetail, error) {
+	if bh == nil || ed == nil {
+		return nil, nil, errors.New("IAT conversion: nil BatchHeader or EntryDetail")
+	}
+	if bh.StandardEntryClassCode == IAT {
+		return nil, nil, errors.New("IAT conversion: entry is already IAT")
+	}
+
+	if conv.ForeignExchangeIndicator == "" {
+		conv.ForeignExchangeIndicator = "FF"
+	}
+	if conv.ForeignExchangeReferenceIndicator == 0 {
+		conv.ForeignExchangeReferenceIndicator = 3
+	}
+	if conv.ISOOriginatingCurrencyCode == "" {
+		conv.ISOOriginatingCurrencyCode = "USD"
+	}
+	if conv.ISODestinationCurrencyCode == "" {
+		conv.ISODestinationCurrencyCode = "USD"
+	}
+	if !iso3166.Valid(conv.ISODestinationCountryCode) {
+		return nil, nil, fmt.Errorf("IAT conversion: %w: %q", ErrValidISO3166, conv.ISODestinationCountryCode)
+	}
+	for _, code := range []string{conv.ISOOriginatingCurrencyCode, conv.ISODestinationCurrencyCode} {
+		if _, exists := iso4217.Lookup(code); !exists {
+			return nil, nil, fmt.Errorf("IAT conversion: %w: %q", ErrValidISO4217, code)
+		}
+	}
+
+	iatBh := NewIATBatchHeader()
+	iatBh.ServiceClassCode = bh.ServiceClassCode
+	iatBh.ForeignExchangeIndicator = conv.ForeignExchangeIndicator
+	iatBh.ForeignExchangeReferenceIndicator = conv.ForeignExchangeReferenceIndicator
+	iatBh.ForeignExchangeReference = conv.ForeignExchangeReference
+	iatBh.ISODestinationCountryCode = conv.ISODestinationCountryCode
+	iatBh.OriginatorIdentification = bh.CompanyIdentification
+	iatBh.StandardEntryClassCode = IAT
+	iatBh.CompanyEntryDescription = bh.CompanyEntryDescription
+	iatBh.ISOOriginatingCurrencyCode = conv.ISOOriginatingCurrencyCode
+	iatBh.ISODestinationCurrencyCode = conv.ISODestinationCurrencyCode
+	iatBh.EffectiveEntryDate = bh.EffectiveEntryDate
+	iatBh.ODFIIdentification = bh.ODFIIdentification
+	iatBh.BatchNumber = bh.BatchNumber
+	if err := iatBh.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("IAT conversion: %w", err)
+	}
+
+	payment := &IATPayment{
+		TransactionCode:     ed.TransactionCode,
+		TransactionTypeCode: conv.TransactionTypeCode,
+		Amount:              ed.Amount,
+		RDFIRoutingNumber:   ed.RDFIIdentificationField() + ed.CheckDigit,
+		DFIAccountNumber:    strings.TrimSpace(ed.DFIAccountNumber),
+		Originator:          conv.Originator,
+		Receiver:            conv.Receiver,
+		ODFI:                conv.ODFI,
+		RDFI:                conv.RDFI,
+		Correspondents:      conv.Correspondents,
+		Remittance:          conv.Remittance,
+	}
+	if payment.TransactionTypeCode == "" {
+		switch bh.StandardEntryClassCode {
+		case ARC, BOC, POP, RCK, TEL, WEB:
+			payment.TransactionTypeCode = bh.StandardEntryClassCode
+		default:
+			return nil, nil, fmt.Errorf("IAT conversion: TransactionTypeCode is required for %s entries", bh.StandardEntryClassCode)
+		}
+	}
+	if payment.Originator.Name == "" {
+		payment.Originator.Name = strings.TrimSpace(bh.CompanyName)
+	}
+	if payment.Receiver.Name == "" {
+		payment.Receiver.Name = strings.TrimSpace(ed.IndividualName)
+	}
+	if payment.Receiver.IdentificationNumber == "" {
+		payment.Receiver.IdentificationNumber = strings.TrimSpace(ed.IdentificationNumber)
+	}
+	if payment.ODFI.Identification == "" {
+		payment.ODFI.IDNumberQualifier, payment.ODFI.BranchCountryCode = "01", "US"
+		payment.ODFI.Identification = withCheckDigit(bh.ODFIIdentificationField())
+	}
+	if payment.RDFI.Identification == "" {
+		payment.RDFI.IDNumberQualifier, payment.RDFI.BranchCountryCode = "01", "US"
+		payment.RDFI.Identification = payment.RDFIRoutingNumber
+	}
+	if len(payment.Remittance) == 0 {
+		if len(ed.Addenda05) > 2 {
+			return nil, nil, fmt.Errorf("IAT conversion: %d Addenda05 records exceed the 2 Addenda17 records of an IAT entry", len(ed.Addenda05))
+		}
+		for i := range ed.Addenda05 {
+			payment.Remittance = append(payment.Remittance, strings.TrimSpace(ed.Addenda05[i].PaymentRelatedInformation))
+		}
+	}
+
+	iatEd, err := payment.IATEntryDetail()
+	if err != nil {
+		return nil, nil, fmt.Errorf("IAT conversion: %w", err)
+	}
+	iatEd.TraceNumber = ed.TraceNumber
+	iatEd.Category = ed.Category
+	return iatBh, iatEd, nil
+}
+
+// ConvertFromIAT converts an inbound IAT entry and its IATBatchHeader into a domestic entry and BatchHeader
+// for posting to the Receiver's account. Entries with the BUS TransactionTypeCode become CCD entries
+// and others PPD entries.
+//
+// The Originator and Receiver names are shortened to the domestic CompanyName and IndividualName
+// lengths. Remittance lines are joined with a space into the single Addenda05 record PPD and CCD
+// entries allow. Remittance over 80 characters and account numbers longer than the 17 characters of
+// a domestic entry are an error.
+func ConvertFromIAT(iatBh *IATBatchHeader, iatEd *IATEntryDetail) (*BatchHeader, *EntryDetail, error) {
+	if iatBh == nil || iatEd == nil {
+		return nil, nil, errors.New("IAT conversion: nil IATBatchHeader or IATEntryDetail")
+	}
+	payment, err := NewIATPayment(iatEd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("IAT conversion: %w", err)
+	}
+	if n := utf8.RuneCountInString(payment.DFIAccountNumber); n > 17 {
+		return nil, nil, fmt.Errorf("IAT conversion: DFIAccountNumber is %d characters, over the domestic limit of 17", n)
+	}
+
+	bh := NewBatchHeader()
+	bh.ServiceClassCode = iatBh.ServiceClassCode
+	bh.CompanyName = truncateRunes(payment.Originator.Name, 16)
+	bh.CompanyIdentification = iatBh.OriginatorIdentification
+	bh.StandardEntryClassCode = PPD
+	if payment.TransactionTypeCode == "BUS" {
+		bh.StandardEntryClassCode = CCD
+	}
+	bh.CompanyEntryDescription = iatBh.CompanyEntryDescription
+	bh.EffectiveEntryDate = iatBh.EffectiveEntryDate
+	bh.ODFIIdentification = iatBh.ODFIIdentification
+	bh.BatchNumber = iatBh.BatchNumber
+
+	ed := NewEntryDetail()
+	ed.TransactionCode = iatEd.TransactionCode
+	ed.SetRDFI(payment.RDFIRoutingNumber)
+	ed.DFIAccountNumber = payment.DFIAccountNumber
+	ed.Amount = iatEd.Amount
+	ed.IdentificationNumber = payment.Receiver.IdentificationNumber
+	ed.IndividualName = truncateRunes(payment.Receiver.Name, 22)
+	ed.TraceNumber = iatEd.TraceNumber
+	ed.Category = iatEd.Category
+	if info := strings.TrimSpace(strings.Join(payment.Remittance, " ")); info != "" {
+		if n := utf8.RuneCountInString(info); n > 80 {
+			return nil, nil, fmt.Errorf("IAT conversion: remittance is %d characters, over the 80 of an Addenda05", n)
+		}
+		addenda05 := NewAddenda05()
+		addenda05.PaymentRelatedInformation = info
+		ed.AddAddenda05(addenda05)
+		ed.AddendaRecordIndicator = 1
+	}
+	return bh, ed, nil
+}
+
+// truncateRunes shortens s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
@@ -0,0 +1,153 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockIATConversion() IATConversion {
+	return IATConversion{
+		ISODestinationCountryCode:  "CA",
+		ISODestinationCurrencyCode: "CAD",
+		TransactionTypeCode:        "SAL",
+		Originator: IATParty{
+			Address: IATAddress{Street: "15 West Place Street", City: "JacobsTown", StateProvince: "PA", CountryCode: "US", PostalCode: "19305"},
+		},
+		Receiver: IATParty{
+			Address: IATAddress{Street: "2121 Front Street", City: "LetterTown", StateProvince: "AB", CountryCode: "CA", PostalCode: "80014"},
+		},
+		ODFI: IATBank{Name: "Wells Fargo"},
+		RDFI: IATBank{Name: "Citadel Bank"},
+	}
+}
+
+func TestConvertToIAT(t *testing.T) {
+	bh := mockBatchPPDHeader()
+	ed := mockPPDEntryDetail()
+	ed.IdentificationNumber = "EMP-1234"
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = "Payroll April"
+	ed.AddAddenda05(addenda05)
+	ed.AddendaRecordIndicator = 1
+
+	iatBh, iatEd, err := ConvertToIAT(bh, ed, mockIATConversion())
+	require.NoError(t, err)
+	require.Equal(t, IAT, iatBh.StandardEntryClassCode)
+	require.Equal(t, "FF", iatBh.ForeignExchangeIndicator)
+	require.Equal(t, 3, iatBh.ForeignExchangeReferenceIndicator)
+	require.Equal(t, "CA", iatBh.ISODestinationCountryCode)
+	require.Equal(t, "USD", iatBh.ISOOriginatingCurrencyCode)
+	require.Equal(t, "CAD", iatBh.ISODestinationCurrencyCode)
+	require.Equal(t, bh.CompanyIdentification, iatBh.OriginatorIdentification)
+
+	require.Equal(t, ed.TraceNumber, iatEd.TraceNumber)
+	require.Equal(t, "ACME Corporation", iatEd.Addenda11.OriginatorName)
+	require.Equal(t, "Wade Arnold", iatEd.Addenda10.Name)
+	require.Equal(t, "EMP-1234", iatEd.Addenda15.ReceiverIDNumber)
+	require.Equal(t, "121042882", iatEd.Addenda13.ODFIIdentification)
+	require.Equal(t, "231380104", iatEd.Addenda14.RDFIIdentification)
+	require.Equal(t, "Payroll April", iatEd.Addenda17[0].PaymentRelatedInformation)
+	require.Equal(t, 8, iatEd.AddendaRecords)
+
+	batch := NewIATBatch(iatBh)
+	batch.AddEntry(iatEd)
+	require.NoError(t, batch.Create())
+
+	// and back to a domestic entry
+	domesticBh, domesticEd, err := ConvertFromIAT(iatBh, iatEd)
+	require.NoError(t, err)
+	require.Equal(t, PPD, domesticBh.StandardEntryClassCode)
+	require.Equal(t, "ACME Corporation", domesticBh.CompanyName)
+	require.Equal(t, ed.TraceNumber, domesticEd.TraceNumber)
+	require.Equal(t, "EMP-1234", domesticEd.IdentificationNumber)
+	require.Equal(t, "Payroll April", domesticEd.Addenda05[0].PaymentRelatedInformation)
+
+	domesticBatch := NewBatchPPD(domesticBh)
+	domesticBatch.AddEntry(domesticEd)
+	require.NoError(t, domesticBatch.Create())
+}
+
+func TestConvertToIAT__Errors(t *testing.T) {
+	bh, ed := mockBatchPPDHeader(), mockPPDEntryDetail()
+
+	conv := mockIATConversion()
+	conv.ISODestinationCountryCode = "ZZ"
+	_, _, err := ConvertToIAT(bh, ed, conv)
+	require.ErrorIs(t, err, ErrValidISO3166)
+
+	conv = mockIATConversion()
+	conv.ISODestinationCurrencyCode = "XYZ"
+	_, _, err = ConvertToIAT(bh, ed, conv)
+	require.ErrorIs(t, err, ErrValidISO4217)
+
+	conv = mockIATConversion()
+	conv.TransactionTypeCode = ""
+	_, _, err = ConvertToIAT(bh, ed, conv)
+	require.ErrorContains(t, err, "TransactionTypeCode")
+
+	bh.StandardEntryClassCode = WEB
+	_, iatEd, err := ConvertToIAT(bh, ed, conv)
+	require.NoError(t, err)
+	require.Equal(t, WEB, iatEd.Addenda10.TransactionTypeCode)
+
+	for i := 0; i < 3; i++ {
+		ed.AddAddenda05(NewAddenda05())
+	}
+	_, _, err = ConvertToIAT(bh, ed, conv)
+	require.ErrorContains(t, err, "Addenda05")
+
+	_, _, err = ConvertToIAT(nil, ed, conv)
+	require.Error(t, err)
+}
+
+func TestConvertFromIAT(t *testing.T) {
+	payment := mockIATPayment()
+	payment.Receiver.Name = "Receiving Company With A Long Name"
+	iatEd, err := payment.IATEntryDetail()
+	require.NoError(t, err)
+
+	bh, ed, err := ConvertFromIAT(mockIATBatchHeaderFF(), iatEd)
+	require.NoError(t, err)
+	require.Equal(t, CCD, bh.StandardEntryClassCode)
+	require.Equal(t, "BEK Solutions", bh.CompanyName)
+	require.Equal(t, "Receiving Company With", ed.IndividualName)
+	require.Len(t, ed.Addenda05, 1)
+	require.Equal(t, "Invoice 12345 Invoice 12346", ed.Addenda05[0].PaymentRelatedInformation)
+
+	ed.SetTraceNumber(bh.ODFIIdentification, 1)
+	batch := NewBatchCCD(bh)
+	batch.AddEntry(ed)
+	require.NoError(t, batch.Create())
+
+	payment.Remittance = []string{strings.Repeat("A", 50), strings.Repeat("B", 50)}
+	iatEd, err = payment.IATEntryDetail()
+	require.NoError(t, err)
+	_, _, err = ConvertFromIAT(mockIATBatchHeaderFF(), iatEd)
+	require.ErrorContains(t, err, "remittance")
+
+	iatEd.DFIAccountNumber = "123456789012345678"
+	_, _, err = ConvertFromIAT(mockIATBatchHeaderFF(), iatEd)
+	require.ErrorContains(t, err, "DFIAccountNumber")
+
+	_, _, err = ConvertFromIAT(mockIATBatchHeaderFF(), NewIATEntryDetail())
+	require.Error(t, err)
+}
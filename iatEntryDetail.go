@@ -53,9 +53,11 @@ type IATEntryDetail struct {
 	// DFIAccountNumber is the receiver's bank account number you are crediting/debiting.
 	// It important to note that this is an alphanumeric field, so its space padded, no zero padded
 	DFIAccountNumber string `json:"DFIAccountNumber"`
-	// OFACScreeningIndicator - Leave blank
+	// OFACScreeningIndicator is set to 1 by the gateway operator when the entry is a suspected OFAC match.
+	// Leave blank when originating, ScreenFile sets it.
 	OFACScreeningIndicator string `json:"OFACScreeningIndicator"`
-	// SecondaryOFACScreeningIndicator - Leave blank
+	// SecondaryOFACScreeningIndicator is set to 1 by a secondary screening when the entry is a suspected OFAC match.
+	// Leave blank when originating.
 	SecondaryOFACScreeningIndicator string `json:"secondaryOFACScreeningIndicator"`
 	// AddendaRecordIndicator indicates the existence of an Addenda Record.
 	// A value of "1" indicates that one or more addenda records follow,
@@ -207,12 +209,10 @@ func (iatEd *IATEntryDetail) Parse(record string) {
 			reset()
 		case 77:
 			// 77 OFACScreeningIndicator
-			reset()
-			iatEd.OFACScreeningIndicator = " "
+			iatEd.OFACScreeningIndicator = reset()
 		case 78:
 			// 78-78 Secondary SecondaryOFACScreeningIndicator
-			reset()
-			iatEd.SecondaryOFACScreeningIndicator = " "
+			iatEd.SecondaryOFACScreeningIndicator = reset()
 		case 79:
 			// 79-79 1 if addenda exists 0 if it does not
 			iatEd.AddendaRecordIndicator = iatEd.parseNumField(reset())
@@ -325,7 +325,16 @@ func (iatEd *IATEntryDetail) SetRDFI(rdfi string) *IATEntryDetail {
 
 // SetTraceNumber takes first 8 digits of ODFI and concatenates a sequence number onto the TraceNumber
 func (iatEd *IATEntryDetail) SetTraceNumber(ODFIIdentification string, seq int) {
-	iatEd.TraceNumber = iatEd.stringField(ODFIIdentification, 8) + iatEd.numericField(seq, 7)
+	traceNumber := iatEd.stringField(ODFIIdentification, 8) + iatEd.numericField(seq, 7)
+	iatEd.TraceNumber = traceNumber
+
+	// Populate TraceNumber of addenda records that should match the Entry's trace number
+	if iatEd.Addenda98 != nil {
+		iatEd.Addenda98.TraceNumber = traceNumber
+	}
+	if iatEd.Addenda99 != nil {
+		iatEd.Addenda99.TraceNumber = traceNumber
+	}
 }
 
 // RDFIIdentificationField get the rdfiIdentification with zero padding
@@ -363,6 +372,11 @@ func (iatEd *IATEntryDetail) TraceNumberField() string {
 	return iatEd.stringField(iatEd.TraceNumber, 15)
 }
 
+// CreditOrDebit returns a "C" for credit or "D" for debit based on the entry TransactionCode
+func (iatEd *IATEntryDetail) CreditOrDebit() string {
+	return creditOrDebit(iatEd.TransactionCode)
+}
+
 // AddAddenda17 appends an Addenda17 to the IATEntryDetail
 func (iatEd *IATEntryDetail) AddAddenda17(addenda17 *Addenda17) {
 	iatEd.Addenda17 = append(iatEd.Addenda17, addenda17)
@@ -372,3 +386,36 @@ func (iatEd *IATEntryDetail) AddAddenda17(addenda17 *Addenda17) {
 func (iatEd *IATEntryDetail) AddAddenda18(addenda18 *Addenda18) {
 	iatEd.Addenda18 = append(iatEd.Addenda18, addenda18)
 }
+
+// addendaCount returns the count of Addenda records added onto this IATEntryDetail
+func (iatEd *IATEntryDetail) addendaCount() (n int) {
+	if iatEd.Addenda10 != nil {
+		n += 1
+	}
+	if iatEd.Addenda11 != nil {
+		n += 1
+	}
+	if iatEd.Addenda12 != nil {
+		n += 1
+	}
+	if iatEd.Addenda13 != nil {
+		n += 1
+	}
+	if iatEd.Addenda14 != nil {
+		n += 1
+	}
+	if iatEd.Addenda15 != nil {
+		n += 1
+	}
+	if iatEd.Addenda16 != nil {
+		n += 1
+	}
+	n += len(iatEd.Addenda17) + len(iatEd.Addenda18)
+	if iatEd.Addenda98 != nil {
+		n += 1
+	}
+	if iatEd.Addenda99 != nil {
+		n += 1
+	}
+	return n
+}
@@ -0,0 +1,264 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// IATAddress is the address of an IAT Originator or Receiver.
+type IATAddress struct {
+	Street        string `json:"street"`
+	City          string `json:"city"`
+	StateProvince string `json:"stateProvince"`
+	// CountryCode is the ISO 3166 two letter country code
+	CountryCode string `json:"countryCode"`
+	PostalCode  string `json:"postalCode"`
+}
+
+// IATParty is the Originator or Receiver of an IAT payment.
+type IATParty struct {
+	Name string `json:"name"`
+	// IdentificationNumber is the Receiver's identification number, it's not written for Originators
+	IdentificationNumber string     `json:"identificationNumber,omitempty"`
+	Address              IATAddress `json:"address"`
+}
+
+// IATBank is a financial institution of an IAT payment. IDNumberQualifier is "01" for a
+// national clearing system number, "02" for a BIC or "03" for an IBAN.
+type IATBank struct {
+	Name              string `json:"name"`
+	IDNumberQualifier string `json:"idNumberQualifier"`
+	Identification    string `json:"identification"`
+	// BranchCountryCode is the ISO 3166 two letter country code of the bank's branch
+	BranchCountryCode string `json:"branchCountryCode"`
+}
+
+// IATPayment describes an IAT entry without its addenda records. Use IATEntryDetail to build
+// the entry with Addenda10 through Addenda18 populated and NewIATPayment to read one.
+type IATPayment struct {
+	TransactionCode int `json:"transactionCode"`
+	// TransactionTypeCode is the Addenda10 payment type, such as BUS, SAL or REM
+	TransactionTypeCode string `json:"transactionTypeCode"`
+	Amount              int    `json:"amount"`
+	ForeignTraceNumber  string `json:"foreignTraceNumber,omitempty"`
+
+	// RDFIRoutingNumber is the 9 digit routing number of the entry's RDFI, or the Gateway Operator of outbound IATs
+	RDFIRoutingNumber string `json:"RDFIRoutingNumber"`
+	DFIAccountNumber  string `json:"DFIAccountNumber"`
+
+	Originator IATParty `json:"originator"`
+	// Receiver is the beneficiary of the payment
+	Receiver IATParty `json:"receiver"`
+
+	ODFI IATBank `json:"ODFI"`
+	RDFI IATBank `json:"RDFI"`
+	// Correspondents are the foreign correspondent banks the payment passes through, at most five
+	Correspondents []IATBank `json:"correspondents,omitempty"`
+
+	// Remittance is at most two lines of 80 characters of payment related information
+	Remittance []string `json:"remittance,omitempty"`
+}
+
+// IATEntryDetail returns an IATEntryDetail with the mandatory Addenda10 through Addenda16, an Addenda17
+// for each remittance line and an Addenda18 for each correspondent bank. AddendaRecords is counted and
+// the sequence numbers of Addenda17 and Addenda18 records are set.
+//
+// Fields which would be truncated when written are an error. Trace numbers are left for the IATBatch
+// to assign and the records are validated by its Create.
+func (p *IATPayment) IATEntryDetail() (*IATEntryDetail, error) {
+	if len(p.Remittance) > 2 {
+		return nil, fmt.Errorf("IATPayment: %d remittance lines exceeds the limit of 2", len(p.Remittance))
+	}
+	if len(p.Correspondents) > 5 {
+		return nil, fmt.Errorf("IATPayment: %d correspondent banks exceeds the limit of 5", len(p.Correspondents))
+	}
+
+	ed := NewIATEntryDetail()
+	ed.TransactionCode = p.TransactionCode
+	ed.SetRDFI(p.RDFIRoutingNumber)
+	ed.Amount = p.Amount
+	ed.DFIAccountNumber = p.DFIAccountNumber
+
+	ed.Addenda10 = NewAddenda10()
+	ed.Addenda10.TransactionTypeCode = p.TransactionTypeCode
+	ed.Addenda10.ForeignPaymentAmount = p.Amount
+	ed.Addenda10.ForeignTraceNumber = p.ForeignTraceNumber
+	ed.Addenda10.Name = p.Receiver.Name
+
+	ed.Addenda11 = NewAddenda11()
+	ed.Addenda11.OriginatorName = p.Originator.Name
+	ed.Addenda11.OriginatorStreetAddress = p.Originator.Address.Street
+
+	ed.Addenda12 = NewAddenda12()
+	ed.Addenda12.OriginatorCityStateProvince = p.Originator.Address.cityStateProvince()
+	ed.Addenda12.OriginatorCountryPostalCode = p.Originator.Address.countryPostalCode()
+
+	ed.Addenda13 = NewAddenda13()
+	ed.Addenda13.ODFIName = p.ODFI.Name
+	ed.Addenda13.ODFIIDNumberQualifier = p.ODFI.IDNumberQualifier
+	ed.Addenda13.ODFIIdentification = p.ODFI.Identification
+	ed.Addenda13.ODFIBranchCountryCode = p.ODFI.BranchCountryCode
+
+	ed.Addenda14 = NewAddenda14()
+	ed.Addenda14.RDFIName = p.RDFI.Name
+	ed.Addenda14.RDFIIDNumberQualifier = p.RDFI.IDNumberQualifier
+	ed.Addenda14.RDFIIdentification = p.RDFI.Identification
+	ed.Addenda14.RDFIBranchCountryCode = p.RDFI.BranchCountryCode
+
+	ed.Addenda15 = NewAddenda15()
+	ed.Addenda15.ReceiverIDNumber = p.Receiver.IdentificationNumber
+	ed.Addenda15.ReceiverStreetAddress = p.Receiver.Address.Street
+
+	ed.Addenda16 = NewAddenda16()
+	ed.Addenda16.ReceiverCityStateProvince = p.Receiver.Address.cityStateProvince()
+	ed.Addenda16.ReceiverCountryPostalCode = p.Receiver.Address.countryPostalCode()
+
+	for i, line := range p.Remittance {
+		addenda17 := NewAddenda17()
+		addenda17.PaymentRelatedInformation = line
+		addenda17.SequenceNumber = i + 1
+		ed.AddAddenda17(addenda17)
+	}
+	for i, bank := range p.Correspondents {
+		addenda18 := NewAddenda18()
+		addenda18.ForeignCorrespondentBankName = bank.Name
+		addenda18.ForeignCorrespondentBankIDNumberQualifier = bank.IDNumberQualifier
+		addenda18.ForeignCorrespondentBankIDNumber = bank.Identification
+		addenda18.ForeignCorrespondentBankBranchCountryCode = bank.BranchCountryCode
+		addenda18.SequenceNumber = i + 1
+		ed.AddAddenda18(addenda18)
+	}
+	ed.AddendaRecords = ed.addendaCount()
+
+	// fields longer than their record positions would be truncated when written
+	type fieldLength struct {
+		field, value string
+		max          int
+	}
+	lengths := []fieldLength{
+		{"Receiver Name", ed.Addenda10.Name, 35},
+		{"ForeignTraceNumber", ed.Addenda10.ForeignTraceNumber, 22},
+		{"Originator Name", ed.Addenda11.OriginatorName, 35},
+		{"Originator Street", ed.Addenda11.OriginatorStreetAddress, 35},
+		{"Originator City and StateProvince", ed.Addenda12.OriginatorCityStateProvince, 35},
+		{"Originator CountryCode and PostalCode", ed.Addenda12.OriginatorCountryPostalCode, 35},
+		{"ODFI Name", ed.Addenda13.ODFIName, 35},
+		{"ODFI Identification", ed.Addenda13.ODFIIdentification, 34},
+		{"RDFI Name", ed.Addenda14.RDFIName, 35},
+		{"RDFI Identification", ed.Addenda14.RDFIIdentification, 34},
+		{"Receiver IdentificationNumber", ed.Addenda15.ReceiverIDNumber, 15},
+		{"Receiver Street", ed.Addenda15.ReceiverStreetAddress, 35},
+		{"Receiver City and StateProvince", ed.Addenda16.ReceiverCityStateProvince, 35},
+		{"Receiver CountryCode and PostalCode", ed.Addenda16.ReceiverCountryPostalCode, 35},
+		{"DFIAccountNumber", ed.DFIAccountNumber, 35},
+	}
+	for _, line := range p.Remittance {
+		lengths = append(lengths, fieldLength{"Remittance", line, 80})
+	}
+	for _, bank := range p.Correspondents {
+		lengths = append(lengths,
+			fieldLength{"Correspondent Name", bank.Name, 35},
+			fieldLength{"Correspondent Identification", bank.Identification, 34},
+		)
+	}
+	for _, l := range lengths {
+		if n := utf8.RuneCountInString(l.value); n > l.max {
+			return nil, fmt.Errorf("IATPayment: %s is %d characters, over the limit of %d", l.field, n, l.max)
+		}
+	}
+
+	return ed, nil
+}
+
+// NewIATPayment reads an IATPayment from an IATEntryDetail and its Addenda10 through Addenda18 records.
+func NewIATPayment(ed *IATEntryDetail) (*IATPayment, error) {
+	if ed == nil {
+		return nil, errors.New("IATPayment: nil IATEntryDetail")
+	}
+	if ed.Addenda10 == nil || ed.Addenda11 == nil || ed.Addenda12 == nil || ed.Addenda13 == nil ||
+		ed.Addenda14 == nil || ed.Addenda15 == nil || ed.Addenda16 == nil {
+		return nil, fmt.Errorf("IATPayment: entry %s is missing mandatory Addenda10-16 records", ed.TraceNumber)
+	}
+	p := &IATPayment{
+		TransactionCode:     ed.TransactionCode,
+		TransactionTypeCode: ed.Addenda10.TransactionTypeCode,
+		Amount:              ed.Amount,
+		ForeignTraceNumber:  strings.TrimSpace(ed.Addenda10.ForeignTraceNumber),
+		RDFIRoutingNumber:   ed.RDFIIdentificationField() + ed.CheckDigit,
+		DFIAccountNumber:    strings.TrimSpace(ed.DFIAccountNumber),
+		Originator: IATParty{
+			Name:    strings.TrimSpace(ed.Addenda11.OriginatorName),
+			Address: parseIATAddress(ed.Addenda11.OriginatorStreetAddress, ed.Addenda12.OriginatorCityStateProvince, ed.Addenda12.OriginatorCountryPostalCode),
+		},
+		Receiver: IATParty{
+			Name:                 strings.TrimSpace(ed.Addenda10.Name),
+			IdentificationNumber: strings.TrimSpace(ed.Addenda15.ReceiverIDNumber),
+			Address:              parseIATAddress(ed.Addenda15.ReceiverStreetAddress, ed.Addenda16.ReceiverCityStateProvince, ed.Addenda16.ReceiverCountryPostalCode),
+		},
+		ODFI: IATBank{
+			Name:              strings.TrimSpace(ed.Addenda13.ODFIName),
+			IDNumberQualifier: ed.Addenda13.ODFIIDNumberQualifier,
+			Identification:    strings.TrimSpace(ed.Addenda13.ODFIIdentification),
+			BranchCountryCode: strings.TrimSpace(ed.Addenda13.ODFIBranchCountryCode),
+		},
+		RDFI: IATBank{
+			Name:              strings.TrimSpace(ed.Addenda14.RDFIName),
+			IDNumberQualifier: ed.Addenda14.RDFIIDNumberQualifier,
+			Identification:    strings.TrimSpace(ed.Addenda14.RDFIIdentification),
+			BranchCountryCode: strings.TrimSpace(ed.Addenda14.RDFIBranchCountryCode),
+		},
+	}
+	for _, addenda17 := range ed.Addenda17 {
+		p.Remittance = append(p.Remittance, strings.TrimSpace(addenda17.PaymentRelatedInformation))
+	}
+	for _, addenda18 := range ed.Addenda18 {
+		p.Correspondents = append(p.Correspondents, IATBank{
+			Name:              strings.TrimSpace(addenda18.ForeignCorrespondentBankName),
+			IDNumberQualifier: addenda18.ForeignCorrespondentBankIDNumberQualifier,
+			Identification:    strings.TrimSpace(addenda18.ForeignCorrespondentBankIDNumber),
+			BranchCountryCode: strings.TrimSpace(addenda18.ForeignCorrespondentBankBranchCountryCode),
+		})
+	}
+	return p, nil
+}
+
+// cityStateProvince formats the Addenda12 and Addenda16 City*State\ field
+func (a IATAddress) cityStateProvince() string {
+	return a.City + "*" + a.StateProvince + `\`
+}
+
+// countryPostalCode formats the Addenda12 and Addenda16 Country*PostalCode\ field
+func (a IATAddress) countryPostalCode() string {
+	return a.CountryCode + "*" + a.PostalCode + `\`
+}
+
+func parseIATAddress(street, cityStateProvince, countryPostalCode string) IATAddress {
+	split := func(s string) (string, string) {
+		s = strings.TrimSuffix(strings.TrimSpace(s), `\`)
+		first, second, _ := strings.Cut(s, "*")
+		return strings.TrimSpace(first), strings.TrimSpace(second)
+	}
+	a := IATAddress{Street: strings.TrimSpace(street)}
+	a.City, a.StateProvince = split(cityStateProvince)
+	a.CountryCode, a.PostalCode = split(countryPostalCode)
+	return a
+}
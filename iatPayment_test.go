@@ -0,0 +1,99 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockIATPayment() *IATPayment {
+	return &IATPayment{
+		TransactionCode:     CheckingCredit,
+		TransactionTypeCode: "BUS",
+		Amount:              100000,
+		RDFIRoutingNumber:   "121042882",
+		DFIAccountNumber:    "123456789",
+		Originator: IATParty{
+			Name:    "BEK Solutions",
+			Address: IATAddress{Street: "15 West Place Street", City: "JacobsTown", StateProvince: "PA", CountryCode: "US", PostalCode: "19305"},
+		},
+		Receiver: IATParty{
+			Name:                 "Receiver Name",
+			IdentificationNumber: "987465493213987",
+			Address:              IATAddress{Street: "2121 Front Street", City: "LetterTown", StateProvince: "AB", CountryCode: "CA", PostalCode: "80014"},
+		},
+		ODFI:           IATBank{Name: "Wells Fargo", IDNumberQualifier: "01", Identification: "231380104", BranchCountryCode: "US"},
+		RDFI:           IATBank{Name: "Citadel Bank", IDNumberQualifier: "01", Identification: "121042882", BranchCountryCode: "CA"},
+		Correspondents: []IATBank{{Name: "Bank of Canada", IDNumberQualifier: "02", Identification: "BOFMCAM2", BranchCountryCode: "CA"}},
+		Remittance:     []string{"Invoice 12345", "Invoice 12346"},
+	}
+}
+
+func TestIATPayment(t *testing.T) {
+	payment := mockIATPayment()
+	ed, err := payment.IATEntryDetail()
+	require.NoError(t, err)
+	require.Equal(t, 10, ed.AddendaRecords)
+	require.Equal(t, "121042882", ed.Addenda14.RDFIIdentification)
+	require.Equal(t, `JacobsTown*PA\`, ed.Addenda12.OriginatorCityStateProvince)
+	require.Equal(t, `CA*80014\`, ed.Addenda16.ReceiverCountryPostalCode)
+	require.Equal(t, 2, ed.Addenda17[1].SequenceNumber)
+	require.Equal(t, 1, ed.Addenda18[0].SequenceNumber)
+
+	batch := NewIATBatch(mockIATBatchHeaderFF())
+	batch.AddEntry(ed)
+	require.NoError(t, batch.Create())
+	require.Equal(t, 1, ed.Addenda16.EntryDetailSequenceNumber)
+
+	file := NewFile()
+	file.SetHeader(mockFileHeader())
+	file.AddIATBatch(batch)
+	require.NoError(t, file.Create())
+
+	// the payment is read back from the written file
+	read, err := NewReader(bytes.NewReader(writeFileBytes(t, file))).Read()
+	require.NoError(t, err)
+	got, err := NewIATPayment(read.IATBatches[0].Entries[0])
+	require.NoError(t, err)
+	require.Equal(t, payment, got)
+
+	_, err = NewIATPayment(NewIATEntryDetail())
+	require.ErrorContains(t, err, "Addenda10-16")
+	_, err = NewIATPayment(nil)
+	require.Error(t, err)
+}
+
+func TestIATPayment__Errors(t *testing.T) {
+	cases := map[string]func(*IATPayment){
+		"remittance lines": func(p *IATPayment) { p.Remittance = append(p.Remittance, "Invoice 12347") },
+		"correspondent":    func(p *IATPayment) { p.Correspondents = make([]IATBank, 6) },
+		"Originator Name":  func(p *IATPayment) { p.Originator.Name = strings.Repeat("A", 36) },
+		"Receiver City":    func(p *IATPayment) { p.Receiver.Address.City = strings.Repeat("A", 32) },
+		"Remittance is 81": func(p *IATPayment) { p.Remittance[0] = strings.Repeat("A", 81) },
+	}
+	for msg, fn := range cases {
+		payment := mockIATPayment()
+		fn(payment)
+		_, err := payment.IATEntryDetail()
+		require.ErrorContains(t, err, msg)
+	}
+}
@@ -0,0 +1,236 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExposureLimit is a daily limit on the total amount of credits and debits in cents.
+// A zero limit is not enforced.
+type ExposureLimit struct {
+	DailyCreditLimit int `json:"dailyCreditLimit"`
+	DailyDebitLimit  int `json:"dailyDebitLimit"`
+}
+
+// OriginatorLimits are the limits of one originator. SECCodes limits apply to the originator's
+// entries of that SEC code in addition to the originator's overall limit.
+type OriginatorLimits struct {
+	ExposureLimit
+	SECCodes map[string]ExposureLimit `json:"secCodes"`
+}
+
+// ExposureLimits are the daily credit and debit limits checked by CheckExposureLimits.
+// They can be read from JSON and set on ValidateOpts to be enforced during validation.
+//
+//	{
+//	  "originators": {
+//	    "121042882": {"dailyCreditLimit": 5000000, "secCodes": {"WEB": {"dailyDebitLimit": 100000}}}
+//	  },
+//	  "secCodes": {"TEL": {"dailyDebitLimit": 2500000}}
+//	}
+type ExposureLimits struct {
+	// Originators are keyed by CompanyIdentification, or OriginatorIdentification for IAT batches
+	Originators map[string]OriginatorLimits `json:"originators"`
+	// SECCodes limits apply to the entries of all originators
+	SECCodes map[string]ExposureLimit `json:"secCodes"`
+}
+
+// LimitBreach describes a daily limit which the entries of an originator or SEC code exceed.
+type LimitBreach struct {
+	// CompanyIdentification is empty for limits across all originators
+	CompanyIdentification string `json:"companyIdentification,omitempty"`
+	// StandardEntryClassCode is empty for an originator's overall limit
+	StandardEntryClassCode string `json:"standardEntryClassCode,omitempty"`
+	EffectiveEntryDate     string `json:"effectiveEntryDate"`
+	// CreditOrDebit is "C" for a credit limit or "D" for a debit limit
+	CreditOrDebit string `json:"creditOrDebit"`
+	Limit         int    `json:"limit"`
+	Total         int    `json:"total"`
+
+	// Entries are the entries which took the total over the limit and every entry counted after them
+	Entries []LimitBreachEntry `json:"entries"`
+}
+
+// LimitBreachEntry references an entry counted in a LimitBreach.
+type LimitBreachEntry struct {
+	FileID      string `json:"fileID,omitempty"`
+	BatchNumber int    `json:"batchNumber"`
+	TraceNumber string `json:"traceNumber"`
+	Amount      int    `json:"amount"`
+}
+
+// ExposureLimitError is returned from validation when ExposureLimits are exceeded.
+type ExposureLimitError struct {
+	Breaches []LimitBreach
+}
+
+func (e *ExposureLimitError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(ErrExposureLimitExceeded.Error())
+	for i, b := range e.Breaches {
+		if i == 0 {
+			buf.WriteString(": ")
+		} else {
+			buf.WriteString("; ")
+		}
+		var who []string
+		if b.CompanyIdentification != "" {
+			who = append(who, "originator "+b.CompanyIdentification)
+		}
+		if b.StandardEntryClassCode != "" {
+			who = append(who, b.StandardEntryClassCode)
+		}
+		kind := "credits"
+		if b.CreditOrDebit == "D" {
+			kind = "debits"
+		}
+		fmt.Fprintf(&buf, "%s %s on %s total %d over limit %d", strings.Join(who, " "), kind, b.EffectiveEntryDate, b.Total, b.Limit)
+	}
+	return buf.String()
+}
+
+func (e *ExposureLimitError) Unwrap() error {
+	return ErrExposureLimitExceeded
+}
+
+type exposureKey struct {
+	company, sec, date, creditOrDebit string
+}
+
+type exposureTotal struct {
+	limit  int
+	total  int
+	breach *LimitBreach
+}
+
+// CheckExposureLimits totals the entries of files by originator, SEC code and EffectiveEntryDate and
+// returns each daily limit they exceed, in the order the limits were exceeded. Pass every file of a
+// day to check limits across them.
+//
+// Returns, Notifications of Change and offset entries are not counted. ADV files are skipped.
+func CheckExposureLimits(limits *ExposureLimits, files ...*File) []LimitBreach {
+	if limits == nil {
+		return nil
+	}
+
+	totals := make(map[exposureKey]*exposureTotal)
+	var breaches []*LimitBreach
+
+	count := func(key exposureKey, limit ExposureLimit, entry LimitBreachEntry) {
+		max := limit.DailyCreditLimit
+		if key.creditOrDebit == "D" {
+			max = limit.DailyDebitLimit
+		}
+		if max <= 0 {
+			return
+		}
+		t, exists := totals[key]
+		if !exists {
+			t = &exposureTotal{limit: max}
+			totals[key] = t
+		}
+		t.total += entry.Amount
+		if t.total <= t.limit {
+			return
+		}
+		if t.breach == nil {
+			t.breach = &LimitBreach{
+				CompanyIdentification:  key.company,
+				StandardEntryClassCode: key.sec,
+				EffectiveEntryDate:     key.date,
+				CreditOrDebit:          key.creditOrDebit,
+				Limit:                  t.limit,
+			}
+			breaches = append(breaches, t.breach)
+		}
+		t.breach.Total = t.total
+		t.breach.Entries = append(t.breach.Entries, entry)
+	}
+
+	add := func(company, sec, date string, transactionCode int, entry LimitBreachEntry) {
+		creditOrDebit := creditOrDebit(transactionCode)
+		if creditOrDebit == "" {
+			return
+		}
+		if originator, exists := limits.Originators[company]; exists {
+			count(exposureKey{company: company, date: date, creditOrDebit: creditOrDebit}, originator.ExposureLimit, entry)
+			if limit, exists := originator.SECCodes[sec]; exists {
+				count(exposureKey{company: company, sec: sec, date: date, creditOrDebit: creditOrDebit}, limit, entry)
+			}
+		}
+		if limit, exists := limits.SECCodes[sec]; exists {
+			count(exposureKey{sec: sec, date: date, creditOrDebit: creditOrDebit}, limit, entry)
+		}
+	}
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		for _, b := range file.Batches {
+			bh := b.GetHeader()
+			for _, ed := range b.GetEntries() {
+				if ed.Offset || ed.Addenda98 != nil || ed.Addenda99 != nil {
+					continue
+				}
+				add(bh.CompanyIdentification, bh.StandardEntryClassCode, bh.EffectiveEntryDate, ed.TransactionCode, LimitBreachEntry{
+					FileID:      file.ID,
+					BatchNumber: bh.BatchNumber,
+					TraceNumber: ed.TraceNumber,
+					Amount:      ed.Amount,
+				})
+			}
+		}
+		for i := range file.IATBatches {
+			bh := file.IATBatches[i].GetHeader()
+			for _, ed := range file.IATBatches[i].Entries {
+				if ed.Addenda98 != nil || ed.Addenda99 != nil {
+					continue
+				}
+				add(bh.OriginatorIdentification, bh.StandardEntryClassCode, bh.EffectiveEntryDate, ed.TransactionCode, LimitBreachEntry{
+					FileID:      file.ID,
+					BatchNumber: bh.BatchNumber,
+					TraceNumber: ed.TraceNumber,
+					Amount:      ed.Amount,
+				})
+			}
+		}
+	}
+
+	if len(breaches) == 0 {
+		return nil
+	}
+	out := make([]LimitBreach, len(breaches))
+	for i := range breaches {
+		out[i] = *breaches[i]
+	}
+	return out
+}
+
+// validate returns an ExposureLimitError when the File exceeds any limits
+func (limits *ExposureLimits) validate(f *File) error {
+	if limits == nil {
+		return nil
+	}
+	if breaches := CheckExposureLimits(limits, f); len(breaches) > 0 {
+		return &ExposureLimitError{Breaches: breaches}
+	}
+	return nil
+}
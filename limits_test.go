@@ -0,0 +1,120 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckExposureLimits(t *testing.T) {
+	// two WEB credit batches with different effective dates and a PPD debit batch
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	file.ID = "web"
+	company := file.Batches[0].GetHeader().CompanyIdentification
+	date := file.Batches[0].GetHeader().EffectiveEntryDate
+
+	webCredits := file.Batches[0].GetControl().TotalCreditEntryDollarAmount
+
+	var limits ExposureLimits
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"originators": {
+			"`+company+`": {"dailyCreditLimit": 100000000, "secCodes": {"WEB": {"dailyCreditLimit": 5000}}}
+		},
+		"secCodes": {"PPD": {"dailyDebitLimit": 100}}
+	}`), &limits))
+
+	breaches := CheckExposureLimits(&limits, file)
+	require.Len(t, breaches, 3)
+
+	web := breaches[0]
+	require.Equal(t, company, web.CompanyIdentification)
+	require.Equal(t, WEB, web.StandardEntryClassCode)
+	require.Equal(t, date, web.EffectiveEntryDate)
+	require.Equal(t, "C", web.CreditOrDebit)
+	require.Equal(t, 5000, web.Limit)
+	require.Equal(t, webCredits, web.Total)
+
+	// the first entry is under the limit, the second takes the total over it
+	entries := file.Batches[0].GetEntries()
+	require.Equal(t, entries[1].TraceNumber, web.Entries[0].TraceNumber)
+	require.Equal(t, "web", web.Entries[0].FileID)
+	require.Equal(t, 1, web.Entries[0].BatchNumber)
+
+	// limits are daily
+	require.Equal(t, file.Batches[1].GetHeader().EffectiveEntryDate, breaches[1].EffectiveEntryDate)
+	require.NotEqual(t, date, breaches[1].EffectiveEntryDate)
+
+	ppd := breaches[2]
+	require.Empty(t, ppd.CompanyIdentification)
+	require.Equal(t, PPD, ppd.StandardEntryClassCode)
+	require.Equal(t, "D", ppd.CreditOrDebit)
+	require.Len(t, ppd.Entries, 1)
+
+	// limits span every file given
+	limits = ExposureLimits{SECCodes: map[string]ExposureLimit{WEB: {DailyCreditLimit: 17500}}}
+	require.Empty(t, CheckExposureLimits(&limits, file))
+	require.Len(t, CheckExposureLimits(&limits, file, file), 2)
+
+	require.Empty(t, CheckExposureLimits(nil, file))
+}
+
+func TestCheckExposureLimits__Offsets(t *testing.T) {
+	file := mockFilePPD(t)
+	file.Batches[0].GetHeader().ServiceClassCode = MixedDebitsAndCredits
+	require.NoError(t, file.Balance(&OffsetTable{
+		Default: &Offset{RoutingNumber: "121042882", AccountNumber: "123456789", AccountType: OffsetChecking},
+	}))
+
+	amount := file.Batches[0].GetEntries()[0].Amount
+	limits := &ExposureLimits{SECCodes: map[string]ExposureLimit{PPD: {DailyCreditLimit: amount, DailyDebitLimit: 1}}}
+	require.Empty(t, CheckExposureLimits(limits, file))
+}
+
+func TestFile__ValidateExposureLimits(t *testing.T) {
+	file := mockFilePPD(t)
+	amount := file.Batches[0].GetEntries()[0].Amount
+	company := file.Batches[0].GetHeader().CompanyIdentification
+
+	opts := &ValidateOpts{
+		ExposureLimits: &ExposureLimits{
+			Originators: map[string]OriginatorLimits{
+				company: {ExposureLimit: ExposureLimit{DailyCreditLimit: amount - 1}},
+			},
+		},
+	}
+	err := file.ValidateWith(opts)
+	require.ErrorIs(t, err, ErrExposureLimitExceeded)
+	require.Contains(t, err.Error(), "originator "+company+" credits")
+
+	var limitErr *ExposureLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Len(t, limitErr.Breaches, 1)
+
+	opts.ExposureLimits.Originators[company] = OriginatorLimits{ExposureLimit: ExposureLimit{DailyCreditLimit: amount}}
+	require.NoError(t, file.ValidateWith(opts))
+
+	// limits set on the File are kept when merging
+	merged := (&ValidateOpts{ExposureLimits: opts.ExposureLimits}).merge(&ValidateOpts{SkipAll: true})
+	require.Equal(t, opts.ExposureLimits, merged.ExposureLimits)
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/igrmk/treemap/v2"
@@ -39,7 +40,8 @@ const NACHAFileLineLimit = 10000
 // Entries with duplicate TraceNumbers are allowed in the same file, but must be in separate batches
 // and are automatically separated.
 //
-// ADV and IAT Batches and Entries are currently not merged together.
+// IAT Batches are merged together when their IATBatchHeaders match (excluding the batch number).
+// ADV Batches are merged into separate files from other Batches as they cannot be mixed.
 //
 // Old rules limit files to 10,000 lines (when rendered in their ASCII encoding), which
 // is the default for this function. Use MergeFilesWith for a higher limit.
@@ -52,6 +54,8 @@ func MergeFiles(files []*File) ([]*File, error) {
 }
 
 // NewMerger returns a Merge which can have custom ValidateOpts
+//
+// The returned Merger also implements ProvenanceMerger.
 func NewMerger(opts *ValidateOpts) Merger {
 	return &merger{opts: opts}
 }
@@ -61,6 +65,12 @@ type Merger interface {
 	MergeWith(files []*File, conditions Conditions) ([]*File, error)
 }
 
+// ProvenanceMerger is a Merger which can also return the MergeProvenance of merged files
+type ProvenanceMerger interface {
+	Merger
+	MergeWithProvenance(files []*File, conditions Conditions) ([]*File, *MergeProvenance, error)
+}
+
 type merger struct {
 	opts *ValidateOpts
 }
@@ -74,12 +84,63 @@ func (m *merger) MergeWith(files []*File, conditions Conditions) ([]*File, error
 	return MergeFilesWith(files, conditions)
 }
 
+func (m *merger) MergeWithProvenance(files []*File, conditions Conditions) ([]*File, *MergeProvenance, error) {
+	if m.opts != nil {
+		for i := range files {
+			files[i].SetValidation(m.opts)
+		}
+	}
+	return MergeFilesWithProvenance(files, conditions)
+}
+
 type Conditions struct {
 	// MaxLines will limit each merged files line count.
 	MaxLines int `json:"maxLines"`
 
 	// MaxDollarAmount will limit each merged file's total dollar amount.
 	MaxDollarAmount int64 `json:"maxDollarAmount"`
+
+	// MaxBatches will limit the number of batches in each merged file.
+	MaxBatches int `json:"maxBatches"`
+
+	// MaxEntries will limit the number of entries (excluding addenda records) in each merged file.
+	MaxEntries int `json:"maxEntries"`
+
+	// SplitByEffectiveEntryDate will produce a separate merged file for each EffectiveEntryDate.
+	SplitByEffectiveEntryDate bool `json:"splitByEffectiveEntryDate"`
+
+	// SeparateSameDay will produce separate merged files for Same Day batches. Batches are Same Day
+	// when their CompanyDescriptiveDate follows the "SDHHMM" convention.
+	SeparateSameDay bool `json:"separateSameDay"`
+
+	// SplitBySECCode will produce a separate merged file for each StandardEntryClassCode.
+	SplitBySECCode bool `json:"splitBySECCode"`
+
+	// SplitByCompanyIdentification will produce a separate merged file for each CompanyIdentification.
+	// IAT batches are split by their OriginatorIdentification.
+	SplitByCompanyIdentification bool `json:"splitByCompanyIdentification"`
+}
+
+// splitKey returns which merged file a batch belongs in according to the Conditions.
+// Batches with the same key can be written into the same file.
+func (c Conditions) splitKey(effectiveEntryDate, secCode, companyIdentification string, sameDay bool) string {
+	var key strings.Builder
+	if c.SplitByEffectiveEntryDate {
+		key.WriteString(effectiveEntryDate)
+	}
+	key.WriteString("|")
+	if c.SeparateSameDay && sameDay {
+		key.WriteString("SD")
+	}
+	key.WriteString("|")
+	if c.SplitBySECCode {
+		key.WriteString(secCode)
+	}
+	key.WriteString("|")
+	if c.SplitByCompanyIdentification {
+		key.WriteString(companyIdentification)
+	}
+	return key.String()
 }
 
 // MergeFilesWith is a function for consolidating an array of ACH Files into a few files as possible.
@@ -91,31 +152,79 @@ type Conditions struct {
 // Entries with duplicate TraceNumbers are allowed in the same file, but must be in separate batches
 // and are automatically separated.
 //
-// ADV and IAT Batches and Entries are currently not merged together.
+// IAT Batches are merged together when their IATBatchHeaders match (excluding the batch number).
+// ADV Batches are merged into separate files from other Batches as they cannot be mixed.
 //
-// Conditions allows for capping the maximum line length or dollar amount of merged files.
+// Conditions allows for capping the maximum line length, dollar amount, batch or entry count of merged files.
+// Merged files can also be split apart by effective entry date, Same Day, SEC code or company identification.
 //
 // File Batches can only be merged if they are unique and routed to and from the same ABA routing numbers.
 func MergeFilesWith(incoming []*File, conditions Conditions) ([]*File, error) {
+	files, _, err := mergeFiles(incoming, conditions, false)
+	return files, err
+}
+
+// MergeFilesWithProvenance merges files the same as MergeFilesWith and also returns a MergeProvenance
+// which maps each entry of the incoming files to the merged file and batch it was placed in.
+func MergeFilesWithProvenance(incoming []*File, conditions Conditions) ([]*File, *MergeProvenance, error) {
+	return mergeFiles(incoming, conditions, true)
+}
+
+func mergeFiles(incoming []*File, conditions Conditions, trackSources bool) ([]*File, *MergeProvenance, error) {
 	if len(incoming) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	sorted := &outFile{
-		header:       incoming[0].Header,
-		validateOpts: incoming[0].GetValidation(),
+		header:          incoming[0].Header,
+		adv:             incoming[0].IsADV(),
+		validateOpts:    incoming[0].GetValidation(),
+		trackSources:    trackSources,
+		separateSameDay: conditions.SeparateSameDay,
 	}
 
 	for i := range incoming {
-		err := sorted.add(incoming[i])
+		err := sorted.add(incoming[i], i)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	return convertToFiles(sorted, conditions)
 }
 
+// MergeProvenance describes where each entry of the files given to MergeFilesWithProvenance or
+// MergeDirWithProvenance was placed
+// in the merged output files. Entries are listed in the order of the merged files and batches.
+type MergeProvenance struct {
+	Entries []MergedEntry `json:"entries"`
+}
+
+// MergedEntry maps an entry from an input file to its position in the merged output files.
+//
+// ADV entries do not have a TraceNumber, so their SequenceNumber fields are set instead.
+type MergedEntry struct {
+	// InputFileIndex is the position of the entry's file in the slice of files merged
+	InputFileIndex int `json:"inputFileIndex"`
+	// InputFileID is the ID of the entry's file, or its path with MergeDirWithProvenance
+	InputFileID string `json:"inputFileID"`
+	// InputBatchNumber is the BatchNumber of the entry's batch in its input file
+	InputBatchNumber int `json:"inputBatchNumber"`
+	// InputTraceNumber is the entry's TraceNumber prior to merging
+	InputTraceNumber string `json:"inputTraceNumber,omitempty"`
+	// InputSequenceNumber is the ADV entry's SequenceNumber prior to merging
+	InputSequenceNumber int `json:"inputSequenceNumber,omitempty"`
+
+	// OutputFileIndex is the position of the merged file the entry was placed in
+	OutputFileIndex int `json:"outputFileIndex"`
+	// OutputBatchNumber is the BatchNumber of the batch the entry was placed in
+	OutputBatchNumber int `json:"outputBatchNumber"`
+	// OutputTraceNumber is the entry's TraceNumber in the merged file
+	OutputTraceNumber string `json:"outputTraceNumber,omitempty"`
+	// OutputSequenceNumber is the ADV entry's SequenceNumber in the merged file
+	OutputSequenceNumber int `json:"outputSequenceNumber,omitempty"`
+}
+
 // MergeDir will consolidate a directory of ACH files into as few files as possible.
 // This is useful for optimizing cost and network utilization.
 //
@@ -125,14 +234,31 @@ func MergeFilesWith(incoming []*File, conditions Conditions) ([]*File, error) {
 // Entries with duplicate TraceNumbers are allowed in the same file, but must be in separate batches
 // and are automatically separated.
 //
-// ADV and IAT Batches and Entries are currently not merged together.
+// IAT Batches are merged together when their IATBatchHeaders match (excluding the batch number).
+// ADV Batches are merged into separate files from other Batches as they cannot be mixed.
 //
 // MergeDir is typically more performant than MergeFiles as it reads files concurrently while merging occurs.
 // This has a more stable cpu and memory usage trend over reading all files into memory and then calling MergeFiles.
 //
 // File Batches can only be merged if they are unique and routed to and from the same ABA routing numbers.
 func MergeDir(dir string, conditions Conditions) ([]*File, error) {
-	sorted := &outFile{}
+	files, _, err := mergeDir(dir, conditions, false)
+	return files, err
+}
+
+// MergeDirWithProvenance merges a directory the same as MergeDir and also returns a MergeProvenance.
+//
+// Files are merged in the order they're read, which can vary between calls, so the InputFileID of each
+// MergedEntry is set to the path of its file instead.
+func MergeDirWithProvenance(dir string, conditions Conditions) ([]*File, *MergeProvenance, error) {
+	return mergeDir(dir, conditions, true)
+}
+
+func mergeDir(dir string, conditions Conditions, trackSources bool) ([]*File, *MergeProvenance, error) {
+	sorted := &outFile{
+		trackSources:    trackSources,
+		separateSameDay: conditions.SeparateSameDay,
+	}
 	var setup sync.Once
 
 	// We've observed the slowest part of MergeDir is reading files from disk and
@@ -196,14 +322,14 @@ func MergeDir(dir string, conditions Conditions) ([]*File, error) {
 
 	// Merge ACH files into the final output
 	g.Go(func() error {
-		for {
+		for fileIndex := 0; ; fileIndex++ {
 			file := <-mergableFiles
 			if file == nil {
 				return nil
 			}
 
 			// accumulate the file into our merged set
-			err := sorted.add(file)
+			err := sorted.add(file, fileIndex)
 			if err != nil {
 				return fmt.Errorf("adding file into merged set failed: %w", err)
 			}
@@ -212,7 +338,7 @@ func MergeDir(dir string, conditions Conditions) ([]*File, error) {
 
 	err := g.Wait()
 	if err != nil {
-		return nil, fmt.Errorf("merging %s failed: %w", dir, err)
+		return nil, nil, fmt.Errorf("merging %s failed: %w", dir, err)
 	}
 
 	return convertToFiles(sorted, conditions)
@@ -231,10 +357,14 @@ func queueFileForMerging(ctx context.Context, discoveredPaths chan string, setup
 			if file == nil || err != nil {
 				return fmt.Errorf("reading %s failed: %w", path, err)
 			}
+			if sorted.trackSources {
+				file.ID = path
+			}
 
 			// Save the first file's header information if it's not already
 			setup.Do(func() {
 				sorted.header = file.Header
+				sorted.adv = file.IsADV()
 				sorted.validateOpts = file.GetValidation()
 			})
 
@@ -251,16 +381,27 @@ func queueFileForMerging(ctx context.Context, discoveredPaths chan string, setup
 
 // outFile is a partial ACH file with batches and forms a linked list to additional files
 type outFile struct {
-	header  FileHeader
-	batches []*batch
+	header     FileHeader
+	batches    []*batch
+	iatBatches []*iatBatch
+	advBatches []*advBatch
+
+	// adv is set when the outFile holds ADV batches, which cannot be mixed with other batches
+	adv bool
+
+	// trackSources records where each entry came from for MergeProvenance
+	trackSources bool
+
+	// separateSameDay keeps Same Day batches apart from other batches
+	separateSameDay bool
 
 	validateOpts *ValidateOpts
 
 	next *outFile
 }
 
-func (outf *outFile) add(incoming *File) error {
-	outFile := pickOutFile(incoming.Header, outf)
+func (outf *outFile) add(incoming *File, fileIndex int) error {
+	outFile := pickOutFile(incoming.Header, incoming.IsADV(), outf)
 	if outFile == nil {
 		return fmt.Errorf("found no outfile: %w", ErrPleaseReportBug)
 	}
@@ -272,10 +413,34 @@ func (outf *outFile) add(incoming *File) error {
 			return fmt.Errorf("batch[%d] has nil BatchHeader", j)
 		}
 
+		if outFile.adv {
+			advEntries := incoming.Batches[j].GetADVEntries()
+			for m := range advEntries {
+				b := findOutADVBatch(bh, outFile.advBatches)
+				if b == nil {
+					b = &advBatch{
+						header: *bh,
+					}
+					outFile.advBatches = append(outFile.advBatches, b)
+				}
+				b.entries = append(b.entries, advEntries[m])
+
+				if outFile.trackSources {
+					b.sources = append(b.sources, MergedEntry{
+						InputFileIndex:      fileIndex,
+						InputFileID:         incoming.ID,
+						InputBatchNumber:    bh.BatchNumber,
+						InputSequenceNumber: advEntries[m].SequenceNumber,
+					})
+				}
+			}
+			continue
+		}
+
 		entries := incoming.Batches[j].GetEntries()
 		for m := range entries {
 			// Find a batch where this entry can fit
-			b := findOutBatch(bh, outFile.batches, entries[m])
+			b := findOutBatch(bh, outFile.batches, entries[m], outFile.separateSameDay)
 
 			// No batch can hold this EntryDetail so create one
 			if b == nil {
@@ -287,129 +452,457 @@ func (outf *outFile) add(incoming *File) error {
 			}
 
 			b.entries.Set(entries[m].TraceNumber, entries[m])
+
+			if outFile.trackSources {
+				if b.sources == nil {
+					b.sources = make(map[string]MergedEntry)
+				}
+				b.sources[entries[m].TraceNumber] = MergedEntry{
+					InputFileIndex:   fileIndex,
+					InputFileID:      incoming.ID,
+					InputBatchNumber: bh.BatchNumber,
+					InputTraceNumber: entries[m].TraceNumber,
+				}
+			}
+		}
+	}
+
+	for j := range incoming.IATBatches {
+		bh := incoming.IATBatches[j].GetHeader()
+		if bh == nil {
+			return fmt.Errorf("IATBatch[%d] has nil IATBatchHeader", j)
+		}
+		signature := iatBatchSignature(bh)
+
+		entries := incoming.IATBatches[j].GetEntries()
+		for m := range entries {
+			// Find an IAT batch where this entry can fit
+			b := findOutIATBatch(signature, outFile.iatBatches, entries[m])
+
+			// No IAT batch can hold this IATEntryDetail so create one
+			if b == nil {
+				b = &iatBatch{
+					header:    *bh,
+					signature: signature,
+					entries:   treemap.New[string, *IATEntryDetail](),
+				}
+				outFile.iatBatches = append(outFile.iatBatches, b)
+			}
+
+			b.entries.Set(entries[m].TraceNumber, entries[m])
+
+			if outFile.trackSources {
+				if b.sources == nil {
+					b.sources = make(map[string]MergedEntry)
+				}
+				b.sources[entries[m].TraceNumber] = MergedEntry{
+					InputFileIndex:   fileIndex,
+					InputFileID:      incoming.ID,
+					InputBatchNumber: bh.BatchNumber,
+					InputTraceNumber: entries[m].TraceNumber,
+				}
+			}
 		}
 	}
 
 	return nil
 }
 
-func convertToFiles(sorted *outFile, conditions Conditions) ([]*File, error) {
-	var batchNumber int
-
-	var out []*File
+func convertToFiles(sorted *outFile, conditions Conditions) ([]*File, *MergeProvenance, error) {
+	out := &mergedFiles{
+		conditions: conditions,
+	}
+	if sorted != nil && sorted.trackSources {
+		out.provenance = &MergeProvenance{}
+	}
 	for {
 		// Run through the linked list (sorted.next) until we terminate
 		if sorted == nil {
 			break
 		}
+		for _, split := range splitOutFile(sorted, conditions) {
+			out.start(sorted)
 
-		file := NewFile()
-		file.Header = sorted.header
+			for i := range split.batches {
+				if err := out.addBatch(split.batches[i]); err != nil {
+					return nil, nil, fmt.Errorf("merging sorted.batches[%d] failed: %w", i, err)
+				}
+			}
+			for i := range split.iatBatches {
+				if err := out.addIATBatch(split.iatBatches[i]); err != nil {
+					return nil, nil, fmt.Errorf("merging sorted.iatBatches[%d] failed: %w", i, err)
+				}
+			}
+			for i := range split.advBatches {
+				if err := out.addADVBatch(split.advBatches[i]); err != nil {
+					return nil, nil, fmt.Errorf("merging sorted.advBatches[%d] failed: %w", i, err)
+				}
+			}
 
-		if sorted.validateOpts != nil {
-			file.SetValidation(sorted.validateOpts)
+			if err := out.closeFile(); err != nil {
+				return nil, nil, fmt.Errorf("problem creating outfile: %w", err)
+			}
 		}
 
-		currentFileLineCount := 2 // FileHeader, FileControl
-		var currentFileDollarAmount int
+		sorted = sorted.next
+	}
+	return out.files, out.provenance, nil
+}
 
-		for i := range sorted.batches {
-			nextBatch := sorted.batches[i]
+// fileSplit holds the batches of an outFile which are written into the same merged files
+type fileSplit struct {
+	batches    []*batch
+	iatBatches []*iatBatch
+	advBatches []*advBatch
+}
 
-			bh := nextBatch.header
-			batchNumber += 1
-			bh.BatchNumber = batchNumber
+// splitOutFile groups the batches of an outFile according to the split Conditions.
+// Groups are returned in the order their first batch was merged.
+func splitOutFile(sorted *outFile, conditions Conditions) []*fileSplit {
+	var out []*fileSplit
+	splits := make(map[string]*fileSplit)
+	find := func(key string) *fileSplit {
+		split, exists := splits[key]
+		if !exists {
+			split = &fileSplit{}
+			splits[key] = split
+			out = append(out, split)
+		}
+		return split
+	}
 
-			batch, err := NewBatch(&bh)
-			if err != nil {
-				return nil, fmt.Errorf("creating batch from sorted.batches[%d] failed: %w", i, err)
-			}
+	for _, b := range sorted.batches {
+		split := find(conditions.splitKey(b.header.EffectiveEntryDate, b.header.StandardEntryClassCode, b.header.CompanyIdentification, b.header.isSameDay()))
+		split.batches = append(split.batches, b)
+	}
+	for _, b := range sorted.iatBatches {
+		split := find(conditions.splitKey(b.header.EffectiveEntryDate, b.header.StandardEntryClassCode, b.header.OriginatorIdentification, false))
+		split.iatBatches = append(split.iatBatches, b)
+	}
+	for _, b := range sorted.advBatches {
+		split := find(conditions.splitKey(b.header.EffectiveEntryDate, b.header.StandardEntryClassCode, b.header.CompanyIdentification, b.header.isSameDay()))
+		split.advBatches = append(split.advBatches, b)
+	}
+	return out
+}
 
-			currentFileLineCount += 2 // BatchHeader, BatchControl
+// mergedFiles accumulates batches into output Files, starting a new File whenever
+// adding the next entry would exceed the merge Conditions.
+type mergedFiles struct {
+	conditions Conditions
 
-			// add each entry detail
-			for it := nextBatch.entries.Iterator(); it.Valid(); it.Next() {
-				nextEntry := it.Value()
+	source *outFile
+	file   *File
+	files  []*File
 
-				// Check if we're going to exceed the merge conditions before adding the entry
-				entryLineCount := 1 + nextEntry.addendaCount()
-				if conditions.MaxLines > 0 {
-					// File will be too large, so make a new file and batch
-					if currentFileLineCount+entryLineCount > conditions.MaxLines {
-						goto overflow
-					}
-				}
+	batchNumber int
 
-				// File would exceed the dollar amount we're limited to
-				if conditions.MaxDollarAmount > 0 {
-					if int64(currentFileDollarAmount)+int64(nextEntry.Amount) > conditions.MaxDollarAmount {
-						goto overflow
-					}
-				}
+	// provenance is non-nil when entries are tracked back to their input files
+	provenance *MergeProvenance
 
-				// Without a condition being exceeded jump into adding the entry in the current batch
-				goto merge
+	currentFileLineCount    int
+	currentFileDollarAmount int
+	currentFileBatchCount   int
+	currentFileEntryCount   int
+}
 
-			overflow:
-				// Close out the current batch and file since we exceeded some limit
-				if len(batch.GetEntries()) > 0 {
-					err = batch.Create()
-					if err != nil {
-						return nil, fmt.Errorf("problem creating batch for new file/batch: %w", err)
-					}
-					file.AddBatch(batch)
-				}
-				if len(file.Batches) > 0 {
-					err = file.Create()
-					if err != nil {
-						return nil, fmt.Errorf("problem creating file for new file/batch: %w", err)
-					}
-					out = append(out, file)
-				}
+// start begins a new File for the given outFile
+func (m *mergedFiles) start(source *outFile) {
+	m.source = source
+	m.file = NewFile()
+	m.file.Header = source.header
 
-				// Reset counters
-				currentFileLineCount = 4 // FileHeader, FileControl, BatchHeader, BatchControl
-				currentFileDollarAmount = 0
+	if source.validateOpts != nil {
+		m.file.SetValidation(source.validateOpts)
+	}
 
-				// Create the new file and batch
-				file = NewFile()
-				file.Header = sorted.header
+	m.currentFileLineCount = 2 // FileHeader, FileControl
+	m.currentFileDollarAmount = 0
+	m.currentFileBatchCount = 0
+	m.currentFileEntryCount = 0
+}
 
-				batch, err = NewBatch(&nextBatch.header)
-				if err != nil {
-					return nil, fmt.Errorf("problem creating overflow batch: %w", err)
-				}
-				batchNumber += 1
-				batch.GetHeader().BatchNumber = batchNumber
+// closeFile will Create the current File and keep it if any batches were added
+func (m *mergedFiles) closeFile() error {
+	if m.file == nil || (len(m.file.Batches) == 0 && len(m.file.IATBatches) == 0) {
+		return nil
+	}
+	if err := m.file.Create(); err != nil {
+		return err
+	}
+	m.files = append(m.files, m.file)
+	m.file = nil
+	return nil
+}
 
-			merge:
-				// Add the entry to the current batch
-				batch.AddEntry(nextEntry)
+// nextBatchNumber returns the ascending batch number to assign for a new batch
+func (m *mergedFiles) nextBatchNumber() int {
+	m.batchNumber += 1
+	return m.batchNumber
+}
+
+// exceeds returns true if adding an entry would exceed the merge conditions
+func (m *mergedFiles) exceeds(lineCount int, amount int) bool {
+	if m.conditions.MaxLines > 0 {
+		// File will be too large
+		if m.currentFileLineCount+lineCount > m.conditions.MaxLines {
+			return true
+		}
+	}
+	if m.conditions.MaxDollarAmount > 0 {
+		// File would exceed the dollar amount we're limited to
+		if int64(m.currentFileDollarAmount)+int64(amount) > m.conditions.MaxDollarAmount {
+			return true
+		}
+	}
+	if m.conditions.MaxEntries > 0 {
+		// File would have too many entries
+		if m.currentFileEntryCount+1 > m.conditions.MaxEntries {
+			return true
+		}
+	}
+	return false
+}
+
+// fullOfBatches returns true if another batch cannot be added to the current File
+func (m *mergedFiles) fullOfBatches() bool {
+	return m.conditions.MaxBatches > 0 && m.currentFileBatchCount >= m.conditions.MaxBatches
+}
+
+// overflow closes out the current file since we exceeded some limit and starts a new File.
+func (m *mergedFiles) overflow() error {
+	if err := m.closeFile(); err != nil {
+		return fmt.Errorf("problem creating file for new file/batch: %w", err)
+	}
+	m.start(m.source)
+	return nil
+}
 
-				currentFileLineCount += 1 + nextEntry.addendaCount()
-				currentFileDollarAmount += nextEntry.Amount
+// startBatch counts a new batch against the current File, starting a new File if the
+// current one cannot hold another batch.
+func (m *mergedFiles) startBatch() error {
+	if m.fullOfBatches() {
+		if err := m.overflow(); err != nil {
+			return err
+		}
+	}
+	m.currentFileLineCount += 2 // BatchHeader, BatchControl
+	m.currentFileBatchCount += 1
+	return nil
+}
+
+// track counts an added entry against the current File
+func (m *mergedFiles) track(lineCount int, amount int) {
+	m.currentFileLineCount += lineCount
+	m.currentFileDollarAmount += amount
+	m.currentFileEntryCount += 1
+}
+
+// recordBatch adds the output location of each entry in a created batch to the provenance.
+// sources must be in the same order as the entries were added to the batch.
+func (m *mergedFiles) recordBatch(sources []MergedEntry, batchNumber int, output func(i int, src *MergedEntry)) {
+	if m.provenance == nil {
+		return
+	}
+	for i := range sources {
+		src := sources[i]
+		src.OutputFileIndex = len(m.files) // the current file is appended next
+		src.OutputBatchNumber = batchNumber
+		output(i, &src)
+		m.provenance.Entries = append(m.provenance.Entries, src)
+	}
+}
+
+func (m *mergedFiles) addBatch(next *batch) error {
+	newBatch := func() (Batcher, error) {
+		bh := next.header
+		bh.BatchNumber = m.nextBatchNumber()
+		return NewBatch(&bh)
+	}
+	var sources []MergedEntry
+	closeBatch := func(b Batcher) error {
+		if len(b.GetEntries()) == 0 {
+			return nil
+		}
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("problem creating batch: %w", err)
+		}
+		entries := b.GetEntries()
+		m.recordBatch(sources, b.GetHeader().BatchNumber, func(i int, src *MergedEntry) {
+			src.OutputTraceNumber = entries[i].TraceNumber
+		})
+		sources = nil
+
+		m.file.AddBatch(b)
+		return nil
+	}
+
+	if err := m.startBatch(); err != nil {
+		return err
+	}
+	b, err := newBatch()
+	if err != nil {
+		return fmt.Errorf("creating batch failed: %w", err)
+	}
+
+	// add each entry detail
+	for it := next.entries.Iterator(); it.Valid(); it.Next() {
+		nextEntry := it.Value()
+
+		// Check if we're going to exceed the merge conditions before adding the entry
+		entryLineCount := 1 + nextEntry.addendaCount()
+		if m.exceeds(entryLineCount, nextEntry.Amount) {
+			if err := closeBatch(b); err != nil {
+				return err
+			}
+			if err := m.overflow(); err != nil {
+				return err
 			}
+			if err := m.startBatch(); err != nil {
+				return err
+			}
+			b, err = newBatch()
+			if err != nil {
+				return fmt.Errorf("problem creating overflow batch: %w", err)
+			}
+		}
 
-			if len(batch.GetEntries()) > 0 {
-				err = batch.Create()
-				if err != nil {
-					return nil, fmt.Errorf("problem creating batch for outfile: %w", err)
-				}
-				file.AddBatch(batch)
+		// Add the entry to the current batch
+		b.AddEntry(nextEntry)
+		m.track(entryLineCount, nextEntry.Amount)
+
+		if m.provenance != nil {
+			sources = append(sources, next.sources[it.Key()])
+		}
+	}
+
+	return closeBatch(b)
+}
+
+func (m *mergedFiles) addIATBatch(next *iatBatch) error {
+	newBatch := func() IATBatch {
+		bh := next.header
+		bh.BatchNumber = m.nextBatchNumber()
+		return NewIATBatch(&bh)
+	}
+	var sources []MergedEntry
+	closeBatch := func(b IATBatch) error {
+		if len(b.GetEntries()) == 0 {
+			return nil
+		}
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("problem creating IAT batch: %w", err)
+		}
+		entries := b.GetEntries()
+		m.recordBatch(sources, b.GetHeader().BatchNumber, func(i int, src *MergedEntry) {
+			src.OutputTraceNumber = entries[i].TraceNumber
+		})
+		sources = nil
+
+		m.file.AddIATBatch(b)
+		return nil
+	}
+
+	if err := m.startBatch(); err != nil {
+		return err
+	}
+	b := newBatch()
+
+	// add each IAT entry detail
+	for it := next.entries.Iterator(); it.Valid(); it.Next() {
+		nextEntry := it.Value()
+
+		// Check if we're going to exceed the merge conditions before adding the entry
+		entryLineCount := 1 + nextEntry.addendaCount()
+		if m.exceeds(entryLineCount, nextEntry.Amount) {
+			if err := closeBatch(b); err != nil {
+				return err
 			}
+			if err := m.overflow(); err != nil {
+				return err
+			}
+			if err := m.startBatch(); err != nil {
+				return err
+			}
+			b = newBatch()
+		}
+
+		// Add the entry to the current IAT batch
+		b.AddEntry(nextEntry)
+		m.track(entryLineCount, nextEntry.Amount)
+
+		if m.provenance != nil {
+			sources = append(sources, next.sources[it.Key()])
+		}
+	}
+
+	return closeBatch(b)
+}
+
+func (m *mergedFiles) addADVBatch(next *advBatch) error {
+	newBatch := func() (Batcher, error) {
+		bh := next.header
+		bh.BatchNumber = m.nextBatchNumber()
+		return NewBatch(&bh)
+	}
+	var sources []MergedEntry
+	closeBatch := func(b Batcher) error {
+		if len(b.GetADVEntries()) == 0 {
+			return nil
 		}
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("problem creating ADV batch: %w", err)
+		}
+		entries := b.GetADVEntries()
+		m.recordBatch(sources, b.GetHeader().BatchNumber, func(i int, src *MergedEntry) {
+			src.OutputSequenceNumber = entries[i].SequenceNumber
+		})
+		sources = nil
 
-		if len(file.Batches) > 0 {
-			err := file.Create()
+		m.file.AddBatch(b)
+		return nil
+	}
+
+	if err := m.startBatch(); err != nil {
+		return err
+	}
+	b, err := newBatch()
+	if err != nil {
+		return fmt.Errorf("creating ADV batch failed: %w", err)
+	}
+
+	for idx, nextEntry := range next.entries {
+		// Check if we're going to exceed the merge conditions before adding the entry
+		entryLineCount := 1
+		if nextEntry.Addenda99 != nil {
+			entryLineCount += 1
+		}
+		if m.exceeds(entryLineCount, nextEntry.Amount) {
+			if err := closeBatch(b); err != nil {
+				return err
+			}
+			if err := m.overflow(); err != nil {
+				return err
+			}
+			if err := m.startBatch(); err != nil {
+				return err
+			}
+			b, err = newBatch()
 			if err != nil {
-				return nil, fmt.Errorf("problem creating outfile: %w", err)
+				return fmt.Errorf("problem creating overflow ADV batch: %w", err)
 			}
-			out = append(out, file)
 		}
 
-		sorted = sorted.next
+		// Add the entry to the current ADV batch
+		b.AddADVEntry(nextEntry)
+		m.track(entryLineCount, nextEntry.Amount)
+
+		if m.provenance != nil {
+			sources = append(sources, next.sources[idx])
+		}
 	}
-	return out, nil
+
+	return closeBatch(b)
 }
 
 // batch contains a BatcHeader and tree of entries sorted by TraceNumber, which allows for
@@ -417,33 +910,72 @@ func convertToFiles(sorted *outFile, conditions Conditions) ([]*File, error) {
 type batch struct {
 	header  BatchHeader
 	entries *treemap.TreeMap[string, *EntryDetail]
+
+	// sources are keyed by TraceNumber and only populated when tracking MergeProvenance
+	sources map[string]MergedEntry
+}
+
+// iatBatch contains an IATBatchHeader and tree of entries sorted by TraceNumber
+type iatBatch struct {
+	header  IATBatchHeader
+	entries *treemap.TreeMap[string, *IATEntryDetail]
+
+	// sources are keyed by TraceNumber and only populated when tracking MergeProvenance
+	sources map[string]MergedEntry
+
+	// signature is the IATBatchHeader excluding the batch number
+	signature string
 }
 
+// advBatch contains a BatchHeader and ADV entries in the order they were read.
+// ADVEntryDetail records have no TraceNumber and are sequenced when the batch is created.
+type advBatch struct {
+	header  BatchHeader
+	entries []*ADVEntryDetail
+
+	// sources match the order of entries and are only populated when tracking MergeProvenance
+	sources []MergedEntry
+}
+
+// advMaxEntriesPerBatch is the most ADVEntryDetail records Batch.build will sequence in one batch
+const advMaxEntriesPerBatch = 9998
+
 // pickOutFile will search for an existing outFile matching the FileHeader Origin and Destination.
+// ADV files are kept apart from other files as their batches cannot be mixed.
 // If no such file can be found it will create one. A nil file will never be returned.
-func pickOutFile(fh FileHeader, file *outFile) *outFile {
+func pickOutFile(fh FileHeader, adv bool, file *outFile) *outFile {
 	if file == nil {
 		return &outFile{
 			header: fh,
+			adv:    adv,
 		}
 	}
 	if fh.ImmediateOrigin == file.header.ImmediateOrigin &&
-		fh.ImmediateDestination == file.header.ImmediateDestination {
+		fh.ImmediateDestination == file.header.ImmediateDestination &&
+		adv == file.adv {
 		return file
 	}
 	if file.next == nil {
 		file.next = &outFile{
-			header: fh,
+			header:          fh,
+			adv:             adv,
+			trackSources:    file.trackSources,
+			separateSameDay: file.separateSameDay,
 		}
 		return file.next
 	}
-	return pickOutFile(fh, file.next)
+	return pickOutFile(fh, adv, file.next)
 }
 
 // findOutBatch searches an array of batches for one whose BatcHeader matches bh
 // and doesn't contain the TraceNumber from entry.
-func findOutBatch(bh *BatchHeader, batches []*batch, entry *EntryDetail) *batch {
+//
+// When separateSameDay is set Same Day batches only match other Same Day batches.
+func findOutBatch(bh *BatchHeader, batches []*batch, entry *EntryDetail, separateSameDay bool) *batch {
 	for i := range batches {
+		if separateSameDay && batches[i].header.isSameDay() != bh.isSameDay() {
+			continue
+		}
 		if batches[i].header.Equal(bh) {
 			// Make sure this batch doesn't contain the TraceNumber already
 			var found bool
@@ -457,3 +989,38 @@ func findOutBatch(bh *BatchHeader, batches []*batch, entry *EntryDetail) *batch
 	}
 	return nil
 }
+
+// iatBatchSignature returns the IATBatchHeader excluding the batch number, which
+// isn't important to preserve when merging.
+func iatBatchSignature(bh *IATBatchHeader) string {
+	return bh.String()[:87]
+}
+
+// findOutIATBatch searches an array of IAT batches for one whose IATBatchHeader matches
+// signature and doesn't contain the TraceNumber from entry.
+func findOutIATBatch(signature string, batches []*iatBatch, entry *IATEntryDetail) *iatBatch {
+	for i := range batches {
+		if batches[i].signature == signature {
+			// Make sure this batch doesn't contain the TraceNumber already
+			var found bool
+			if entry != nil {
+				found = batches[i].entries.Contains(entry.TraceNumber)
+			}
+			if !found {
+				return batches[i]
+			}
+		}
+	}
+	return nil
+}
+
+// findOutADVBatch searches an array of ADV batches for one whose BatchHeader matches bh
+// and has room for another entry.
+func findOutADVBatch(bh *BatchHeader, batches []*advBatch) *advBatch {
+	for i := range batches {
+		if batches[i].header.Equal(bh) && len(batches[i].entries) < advMaxEntriesPerBatch {
+			return batches[i]
+		}
+	}
+	return nil
+}
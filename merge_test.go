@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
 	"path/filepath"
 	"strconv"
 	"testing"
@@ -544,7 +545,7 @@ func TestMergeFilesHelpers(t *testing.T) {
 		fh := mockFileHeader()
 		var input *outFile
 
-		output := pickOutFile(fh, input)
+		output := pickOutFile(fh, false, input)
 		require.Equal(t, fh, output.header)
 		require.Empty(t, output.batches)
 		require.Nil(t, output.next)
@@ -552,11 +553,11 @@ func TestMergeFilesHelpers(t *testing.T) {
 		input = &outFile{
 			header: mockFileHeader(),
 		}
-		require.Equal(t, input, pickOutFile(fh, input))
+		require.Equal(t, input, pickOutFile(fh, false, input))
 
 		fh2 := mockFileHeader()
 		fh2.ImmediateOrigin = "123456780"
-		output = pickOutFile(fh2, input)
+		output = pickOutFile(fh2, false, input)
 		require.Equal(t, output, input.next) // verify the chain continues
 		require.Equal(t, fh2, output.header)
 		require.Empty(t, output.batches)
@@ -564,8 +565,14 @@ func TestMergeFilesHelpers(t *testing.T) {
 
 		fh3 := mockFileHeader()
 		fh3.ImmediateDestination = "123456780"
-		output = pickOutFile(fh3, input)
+		output = pickOutFile(fh3, false, input)
 		require.Equal(t, fh3, output.header)
+
+		// ADV files are kept apart
+		output = pickOutFile(fh, true, input)
+		require.NotEqual(t, input, output)
+		require.True(t, output.adv)
+		require.Equal(t, fh, output.header)
 	})
 
 	t.Run("findOutBatch", func(t *testing.T) {
@@ -573,7 +580,7 @@ func TestMergeFilesHelpers(t *testing.T) {
 		var batches []*batch
 		var entry *EntryDetail
 
-		output := findOutBatch(bh, batches, entry)
+		output := findOutBatch(bh, batches, entry, false)
 		require.Nil(t, output)
 
 		// find the batch
@@ -581,7 +588,7 @@ func TestMergeFilesHelpers(t *testing.T) {
 			header:  *bh,
 			entries: treemap.New[string, *EntryDetail](),
 		})
-		output = findOutBatch(bh, batches, entry)
+		output = findOutBatch(bh, batches, entry, false)
 		require.Equal(t, batches[0], output)
 
 		// add an entry to the batch
@@ -595,7 +602,337 @@ func TestMergeFilesHelpers(t *testing.T) {
 		// exclude the batch when the trace number is found
 		output = findOutBatch(bh, batches, &EntryDetail{
 			TraceNumber: traceNumber,
-		})
+		}, false)
 		require.Nil(t, output)
 	})
 }
+
+func TestMergeFiles__IAT(t *testing.T) {
+	f1, err := readACHFilepath(filepath.Join("test", "testdata", "iat-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+	require.Len(t, f1.IATBatches, 1)
+
+	f2, err := readACHFilepath(filepath.Join("test", "testdata", "iat-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+
+	// Identical trace numbers must be kept in separate batches
+	out, err := MergeFiles([]*File{f1, f2})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Len(t, out[0].IATBatches, 2)
+	require.NoError(t, out[0].Validate())
+
+	// Change the trace numbers so the entries are merged into one batch
+	f3, err := readACHFilepath(filepath.Join("test", "testdata", "iat-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+	for _, entry := range f3.IATBatches[0].Entries {
+		n, _ := strconv.Atoi(entry.TraceNumber)
+		entry.TraceNumber = strconv.Itoa(n + 100)
+	}
+	out, err = MergeFiles([]*File{f1, f3})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Len(t, out[0].IATBatches, 1)
+	require.Len(t, out[0].IATBatches[0].Entries, 2*len(f1.IATBatches[0].Entries))
+	require.NoError(t, out[0].Validate())
+
+	t.Run("with domestic batches", func(t *testing.T) {
+		ppd, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+		require.NoError(t, err)
+		ppd.Header = f1.Header
+
+		out, err := MergeFiles([]*File{ppd, f1})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+		require.Len(t, out[0].Batches, 1)
+		require.Len(t, out[0].IATBatches, 1)
+		require.NoError(t, out[0].Validate())
+	})
+
+	t.Run("MaxLines", func(t *testing.T) {
+		perEntry := 1 + f1.IATBatches[0].Entries[0].addendaCount()
+
+		out, err := MergeFilesWith([]*File{f1, f3}, Conditions{
+			MaxLines: 4 + perEntry, // FileHeader, FileControl, BatchHeader, BatchControl
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 2*len(f1.IATBatches[0].Entries))
+		for i := range out {
+			require.Len(t, out[i].IATBatches, 1)
+			require.Len(t, out[i].IATBatches[0].Entries, 1)
+			require.LessOrEqual(t, lineCount(out[i]), 4+perEntry)
+			require.NoError(t, out[i].Validate())
+		}
+	})
+}
+
+func TestMergeFiles__ADV(t *testing.T) {
+	f1, err := readACHFilepath(filepath.Join("test", "ach-adv-read", "adv-read.ach"))
+	require.NoError(t, err)
+	require.True(t, f1.IsADV())
+
+	f2, err := readACHFilepath(filepath.Join("test", "ach-adv-read", "adv-read.ach"))
+	require.NoError(t, err)
+
+	ppd, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+	ppd.Header = f1.Header
+
+	out, err := MergeFiles([]*File{f1, ppd, f2})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+
+	// ADV files are kept apart from other batches
+	require.True(t, out[0].IsADV())
+	require.Len(t, out[0].Batches, 1)
+	expected := len(f1.Batches[0].GetADVEntries()) + len(f2.Batches[0].GetADVEntries())
+	require.Len(t, out[0].Batches[0].GetADVEntries(), expected)
+	require.NoError(t, out[0].Validate())
+
+	require.False(t, out[1].IsADV())
+	require.Len(t, out[1].Batches, 1)
+	require.NoError(t, out[1].Validate())
+
+	t.Run("MaxDollarAmount", func(t *testing.T) {
+		var max int
+		for _, entry := range f1.Batches[0].GetADVEntries() {
+			if entry.Amount > max {
+				max = entry.Amount
+			}
+		}
+		out, err := MergeFilesWith([]*File{f1, f2}, Conditions{
+			MaxDollarAmount: int64(max),
+		})
+		require.NoError(t, err)
+		require.Greater(t, len(out), 1)
+
+		var found int
+		for i := range out {
+			require.True(t, out[i].IsADV())
+			require.NoError(t, out[i].Validate())
+			for _, b := range out[i].Batches {
+				found += len(b.GetADVEntries())
+			}
+		}
+		require.Equal(t, expected, found)
+	})
+}
+
+func TestMergeFiles__Provenance(t *testing.T) {
+	f1, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+	f1.ID = "first"
+
+	f2, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	f2.ID = "second"
+	f2.Header = f1.Header // replace Header so they're merged into one file
+
+	f3, err := readACHFilepath(filepath.Join("test", "testdata", "iat-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+	f3.ID = "third"
+
+	out, provenance, err := MergeFilesWithProvenance([]*File{f1, f2, f3}, Conditions{
+		MaxLines: 15,
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(out), 2)
+	require.NotNil(t, provenance)
+
+	var expected int
+	for _, f := range []*File{f1, f2} {
+		expected += countTraceNumbers(f)
+	}
+	for _, b := range f3.IATBatches {
+		expected += len(b.Entries)
+	}
+	require.Len(t, provenance.Entries, expected)
+
+	for _, entry := range provenance.Entries {
+		require.Contains(t, []string{"first", "second", "third"}, entry.InputFileID)
+		require.Equal(t, entry.InputFileID, []*File{f1, f2, f3}[entry.InputFileIndex].ID)
+		require.NotEmpty(t, entry.InputTraceNumber)
+
+		// Find the entry in our output
+		merged := out[entry.OutputFileIndex]
+		var found bool
+		for _, b := range merged.Batches {
+			if b.GetHeader().BatchNumber != entry.OutputBatchNumber {
+				continue
+			}
+			for _, ed := range b.GetEntries() {
+				found = found || ed.TraceNumber == entry.OutputTraceNumber
+			}
+		}
+		for _, b := range merged.IATBatches {
+			if b.GetHeader().BatchNumber != entry.OutputBatchNumber {
+				continue
+			}
+			for _, ed := range b.GetEntries() {
+				found = found || ed.TraceNumber == entry.OutputTraceNumber
+			}
+		}
+		require.True(t, found, "entry %#v not found", entry)
+	}
+
+	t.Run("ADV", func(t *testing.T) {
+		adv, err := readACHFilepath(filepath.Join("test", "ach-adv-read", "adv-read.ach"))
+		require.NoError(t, err)
+
+		merger, ok := NewMerger(nil).(ProvenanceMerger)
+		require.True(t, ok)
+		out, provenance, err := merger.MergeWithProvenance([]*File{adv}, Conditions{})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+
+		entries := adv.Batches[0].GetADVEntries()
+		require.Len(t, provenance.Entries, len(entries))
+		for i, entry := range provenance.Entries {
+			require.Equal(t, 0, entry.OutputFileIndex)
+			require.Empty(t, entry.InputTraceNumber)
+			require.Equal(t, i+1, entry.OutputSequenceNumber)
+		}
+	})
+
+	t.Run("MergeDir", func(t *testing.T) {
+		dir := t.TempDir()
+		for i, f := range []*File{f1, f2, f3} {
+			path := filepath.Join(dir, fmt.Sprintf("%d.ach", i))
+			require.NoError(t, os.WriteFile(path, writeFileBytes(t, f), 0600))
+		}
+
+		out, provenance, err := MergeDirWithProvenance(dir, Conditions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, out)
+
+		require.Len(t, provenance.Entries, expected)
+		for _, entry := range provenance.Entries {
+			require.Equal(t, dir, filepath.Dir(entry.InputFileID))
+			require.NotEmpty(t, entry.OutputTraceNumber)
+		}
+	})
+
+	t.Run("not tracked", func(t *testing.T) {
+		_, provenance, err := mergeFiles([]*File{f1}, Conditions{}, false)
+		require.NoError(t, err)
+		require.Nil(t, provenance)
+	})
+}
+
+func TestMergeFiles__SplitConditions(t *testing.T) {
+	read := func(t *testing.T) []*File {
+		t.Helper()
+
+		f1, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+		require.NoError(t, err)
+
+		f2, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+		require.NoError(t, err)
+		f2.Header = f1.Header // replace Header so they're merged into one file
+
+		return []*File{f1, f2}
+	}
+
+	t.Run("none", func(t *testing.T) {
+		out, err := MergeFilesWith(read(t), Conditions{})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+		require.Len(t, out[0].Batches, 4)
+	})
+
+	t.Run("SplitByEffectiveEntryDate", func(t *testing.T) {
+		out, err := MergeFilesWith(read(t), Conditions{
+			SplitByEffectiveEntryDate: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 4)
+		for i := range out {
+			require.NoError(t, out[i].Validate())
+			require.Len(t, out[i].Batches, 1)
+		}
+	})
+
+	t.Run("SplitBySECCode", func(t *testing.T) {
+		out, err := MergeFilesWith(read(t), Conditions{
+			SplitBySECCode: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		for i := range out {
+			require.NoError(t, out[i].Validate())
+			sec := out[i].Batches[0].GetHeader().StandardEntryClassCode
+			for _, b := range out[i].Batches {
+				require.Equal(t, sec, b.GetHeader().StandardEntryClassCode)
+			}
+		}
+		require.Len(t, out[0].Batches, 2) // PPD
+		require.Len(t, out[1].Batches, 2) // WEB
+	})
+
+	t.Run("SplitByCompanyIdentification", func(t *testing.T) {
+		out, err := MergeFilesWith(read(t), Conditions{
+			SplitByCompanyIdentification: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		require.Len(t, out[0].Batches, 1)
+		require.Len(t, out[1].Batches, 3)
+	})
+
+	t.Run("SeparateSameDay", func(t *testing.T) {
+		files := read(t)
+
+		// A Same Day copy of ppd-debit.ach with new trace numbers
+		sd, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+		require.NoError(t, err)
+		sd.Batches[0].GetHeader().CompanyDescriptiveDate = "SD1300"
+		for _, entry := range sd.Batches[0].GetEntries() {
+			n, _ := strconv.Atoi(entry.TraceNumber)
+			entry.TraceNumber = strconv.Itoa(n + 100)
+		}
+		files = append(files, sd)
+
+		// Without the condition the Same Day entries merge into the first batch
+		out, err := MergeFilesWith(files, Conditions{})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+		require.Len(t, out[0].Batches, 4)
+
+		out, err = MergeFilesWith(files, Conditions{
+			SeparateSameDay: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		require.Len(t, out[0].Batches, 4)
+		require.Len(t, out[1].Batches, 1)
+		require.Equal(t, "SD1300", out[1].Batches[0].GetHeader().CompanyDescriptiveDate)
+	})
+
+	t.Run("MaxBatches", func(t *testing.T) {
+		out, err := MergeFilesWith(read(t), Conditions{
+			MaxBatches: 3,
+		})
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		require.Len(t, out[0].Batches, 3)
+		require.Len(t, out[1].Batches, 1)
+		for i := range out {
+			require.NoError(t, out[i].Validate())
+		}
+	})
+
+	t.Run("MaxEntries", func(t *testing.T) {
+		files := read(t)
+		expected := countTraceNumbers(files...)
+
+		out, err := MergeFilesWith(files, Conditions{
+			MaxEntries: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, countTraceNumbers(out...))
+		for i := range out {
+			require.NoError(t, out[i].Validate())
+			require.LessOrEqual(t, countTraceNumbers(out[i]), 2)
+		}
+	})
+}
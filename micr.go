@@ -0,0 +1,220 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MICR holds the fields of a check's E-13B MICR line used to convert it into an ACH entry.
+type MICR struct {
+	// AuxiliaryOnUs is the field left of the routing number on business checks, usually the check serial number
+	AuxiliaryOnUs string `json:"auxiliaryOnUs,omitempty"`
+	// ExternalProcessingCode is the single digit between the auxiliary on-us and transit fields
+	ExternalProcessingCode string `json:"externalProcessingCode,omitempty"`
+	// RoutingNumber is the 9 digit transit field, including the check digit
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is the on-us field's account number with spaces removed
+	AccountNumber string `json:"accountNumber"`
+	// CheckSerialNumber is AuxiliaryOnUs when present, otherwise the serial number in the on-us field of personal checks
+	CheckSerialNumber string `json:"checkSerialNumber,omitempty"`
+	// Amount is the encoded amount field in cents, zero when the check hasn't been encoded
+	Amount int `json:"amount,omitempty"`
+}
+
+// MICR line symbols after normalizing
+const (
+	micrTransit = 'T'
+	micrOnUs    = 'U'
+	micrAmount  = 'A'
+	micrDash    = '-'
+)
+
+// normalizeMICR maps the E-13B symbols (⑆ ⑈ ⑇ ⑉) and the ASCII letters check scanners use
+// in their place to T (transit), U (on-us), A (amount) and - (dash).
+func normalizeMICR(line string) (string, error) {
+	var buf strings.Builder
+	for _, r := range line {
+		switch {
+		case r >= '0' && r <= '9', r == ' ':
+			buf.WriteRune(r)
+		case r == '⑆' || r == 'T' || r == 't':
+			buf.WriteRune(micrTransit)
+		case r == '⑈' || r == 'U' || r == 'u' || r == 'O' || r == 'o':
+			buf.WriteRune(micrOnUs)
+		case r == '⑇' || r == 'A' || r == 'a' || r == '$':
+			buf.WriteRune(micrAmount)
+		case r == '⑉' || r == 'D' || r == 'd' || r == '-':
+			buf.WriteRune(micrDash)
+		case r == '\t':
+			buf.WriteRune(' ')
+		default:
+			return "", fmt.Errorf("%w: unexpected character %q", ErrInvalidMICR, r)
+		}
+	}
+	return buf.String(), nil
+}
+
+// ParseMICR reads an E-13B MICR line such as
+//
+//	⑈001234⑈ ⑆121042882⑆ 123 456 789⑈ ⑇0000012345⑇
+//
+// The on-us symbol can be written as U or O, the transit symbol as T, the amount symbol as A or $
+// and the dash symbol as D or -. The routing number's check digit must be valid.
+func ParseMICR(line string) (*MICR, error) {
+	s, err := normalizeMICR(line)
+	if err != nil {
+		return nil, err
+	}
+
+	start := strings.IndexRune(s, micrTransit)
+	end := -1
+	if start >= 0 {
+		if i := strings.IndexRune(s[start+1:], micrTransit); i >= 0 {
+			end = start + 1 + i
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("%w: missing transit field", ErrInvalidMICR)
+	}
+
+	m := &MICR{}
+	m.RoutingNumber = strings.NewReplacer(" ", "", string(micrDash), "").Replace(s[start+1 : end])
+	if err := CheckRoutingNumber(m.RoutingNumber); err != nil {
+		return nil, fmt.Errorf("%w: transit field: %v", ErrInvalidMICR, err)
+	}
+
+	// auxiliary on-us and external processing code
+	prefix := s[:start]
+	if i := strings.IndexRune(prefix, micrOnUs); i >= 0 {
+		j := strings.IndexRune(prefix[i+1:], micrOnUs)
+		if j < 0 {
+			return nil, fmt.Errorf("%w: unterminated auxiliary on-us field", ErrInvalidMICR)
+		}
+		if strings.TrimSpace(prefix[:i]) != "" {
+			return nil, fmt.Errorf("%w: unexpected characters before auxiliary on-us field", ErrInvalidMICR)
+		}
+		m.AuxiliaryOnUs = strings.ReplaceAll(prefix[i+1:i+1+j], " ", "")
+		prefix = prefix[i+1+j+1:]
+	}
+	m.ExternalProcessingCode = strings.TrimSpace(prefix)
+	if len(m.ExternalProcessingCode) > 1 || strings.ContainsAny(m.ExternalProcessingCode, " "+string(micrDash)) {
+		return nil, fmt.Errorf("%w: invalid external processing code %q", ErrInvalidMICR, m.ExternalProcessingCode)
+	}
+
+	// amount
+	rest := s[end+1:]
+	if i := strings.IndexRune(rest, micrAmount); i >= 0 {
+		j := strings.IndexRune(rest[i+1:], micrAmount)
+		if j < 0 {
+			return nil, fmt.Errorf("%w: unterminated amount field", ErrInvalidMICR)
+		}
+		amount := strings.TrimSpace(rest[i+1 : i+1+j])
+		if m.Amount, err = strconv.Atoi(amount); err != nil || m.Amount < 0 {
+			return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidMICR, amount)
+		}
+		if strings.TrimSpace(rest[i+1+j+1:]) != "" {
+			return nil, fmt.Errorf("%w: unexpected characters after amount field", ErrInvalidMICR)
+		}
+		rest = rest[:i]
+	}
+
+	// on-us field: the account number followed by the serial number on personal checks
+	var parts []string
+	for _, p := range strings.Split(rest, string(micrOnUs)) {
+		if p = strings.ReplaceAll(p, " ", ""); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: missing on-us field", ErrInvalidMICR)
+	}
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("%w: unexpected on-us field %q", ErrInvalidMICR, strings.Join(parts[2:], " "))
+	}
+	m.AccountNumber = parts[0]
+	if len(parts) == 2 {
+		m.CheckSerialNumber = parts[1]
+	}
+	if m.AuxiliaryOnUs != "" {
+		m.CheckSerialNumber = m.AuxiliaryOnUs
+	}
+	return m, nil
+}
+
+// checkEntry returns a debit to the checking account of the MICR line
+func (m *MICR) checkEntry(amount int) (*EntryDetail, error) {
+	if m == nil {
+		return nil, fmt.Errorf("%w: nil MICR", ErrInvalidMICR)
+	}
+	if amount <= 0 {
+		amount = m.Amount
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: no amount", ErrInvalidMICR)
+	}
+	if m.CheckSerialNumber == "" {
+		return nil, ErrBatchCheckSerialNumber
+	}
+	ed := NewEntryDetail()
+	ed.TransactionCode = CheckingDebit
+	ed.SetRDFI(m.RoutingNumber)
+	ed.DFIAccountNumber = m.AccountNumber
+	ed.Amount = amount
+	ed.Category = CategoryForward
+	return ed, nil
+}
+
+// NewCheckEntry returns an ARC, BOC or RCK debit for the check read from its MICR line with the
+// check serial number set. A zero amount uses the MICR line's encoded Amount.
+//
+// IndividualName and the trace number are left for the caller to set.
+func NewCheckEntry(sec string, m *MICR, amount int) (*EntryDetail, error) {
+	switch sec {
+	case ARC, BOC, RCK:
+	default:
+		return nil, fmt.Errorf("%s entries can't be created from a MICR line, use NewPOPEntry for POP", sec)
+	}
+	ed, err := m.checkEntry(amount)
+	if err != nil {
+		return nil, err
+	}
+	ed.SetCheckSerialNumber(m.CheckSerialNumber)
+	return ed, nil
+}
+
+// NewPOPEntry returns a POP debit for the check read from its MICR line with the check serial number
+// and the city and state of the terminal where the check was converted. A zero amount uses the
+// MICR line's encoded Amount.
+//
+// POP check serial numbers are 9 characters, the terminal city 4 and the terminal state 2.
+func NewPOPEntry(m *MICR, amount int, terminalCity, terminalState string) (*EntryDetail, error) {
+	ed, err := m.checkEntry(amount)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.CheckSerialNumber) > 9 {
+		return nil, fmt.Errorf("%w: POP check serial number %s is over 9 characters", ErrInvalidMICR, m.CheckSerialNumber)
+	}
+	ed.SetPOPCheckSerialNumber(m.CheckSerialNumber)
+	ed.SetPOPTerminalCity(terminalCity)
+	ed.SetPOPTerminalState(terminalState)
+	return ed, nil
+}
@@ -0,0 +1,128 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMICR(t *testing.T) {
+	// business check with an auxiliary on-us serial number and encoded amount
+	m, err := ParseMICR("⑈001234⑈ 5⑆231380104⑆ 744⑉5678 99⑈ ⑇0000012345⑇")
+	require.NoError(t, err)
+	require.Equal(t, &MICR{
+		AuxiliaryOnUs:          "001234",
+		ExternalProcessingCode: "5",
+		RoutingNumber:          "231380104",
+		AccountNumber:          "744-567899",
+		CheckSerialNumber:      "001234",
+		Amount:                 12345,
+	}, m)
+
+	// personal check with the serial number after the account, using scanner ASCII symbols
+	m, err = ParseMICR("T121042882T 123456789U 1001")
+	require.NoError(t, err)
+	require.Equal(t, "121042882", m.RoutingNumber)
+	require.Equal(t, "123456789", m.AccountNumber)
+	require.Equal(t, "1001", m.CheckSerialNumber)
+	require.Zero(t, m.Amount)
+	require.Empty(t, m.AuxiliaryOnUs)
+
+	cases := []string{
+		"",
+		"123456789U 1001",
+		"T121042881T 123456789U 1001",
+		"T121042882T",
+		"U001234 T121042882T 123456789U",
+		"12 T121042882T 123456789U",
+		"T121042882T 123456789U 1001 A00000123",
+		"T121042882T 123456789U 1001 A0000012x45A",
+		"T121042882T 123U456U789U",
+		"T121042882T 123456789# 1001",
+	}
+	for _, line := range cases {
+		_, err := ParseMICR(line)
+		require.ErrorIs(t, err, ErrInvalidMICR, line)
+	}
+}
+
+func TestNewCheckEntry(t *testing.T) {
+	m, err := ParseMICR("⑈001234⑈ ⑆231380104⑆ 744⑉5678 99⑈ ⑇0000012345⑇")
+	require.NoError(t, err)
+
+	for _, sec := range []string{ARC, BOC, RCK} {
+		ed, err := NewCheckEntry(sec, m, 0)
+		require.NoError(t, err)
+		require.Equal(t, CheckingDebit, ed.TransactionCode)
+		require.Equal(t, "23138010", ed.RDFIIdentification)
+		require.Equal(t, "4", ed.CheckDigit)
+		require.Equal(t, "744-567899", ed.DFIAccountNumber)
+		require.Equal(t, "001234", ed.IdentificationNumber)
+		require.Equal(t, 12345, ed.Amount)
+
+		ed.IndividualName = "Check Writer"
+		ed.SetTraceNumber("12104288", 1)
+		bh := mockBatchARCHeader()
+		bh.StandardEntryClassCode = sec
+		if sec == RCK {
+			bh.CompanyEntryDescription = "REDEPCHECK"
+		}
+		batch, err := NewBatch(bh)
+		require.NoError(t, err)
+		batch.AddEntry(ed)
+		require.NoError(t, batch.Create(), sec)
+	}
+
+	ed, err := NewCheckEntry(BOC, m, 500)
+	require.NoError(t, err)
+	require.Equal(t, 500, ed.Amount)
+
+	_, err = NewCheckEntry(PPD, m, 0)
+	require.ErrorContains(t, err, "PPD")
+
+	m.Amount = 0
+	_, err = NewCheckEntry(ARC, m, 0)
+	require.ErrorIs(t, err, ErrInvalidMICR)
+
+	m.CheckSerialNumber = ""
+	_, err = NewCheckEntry(ARC, m, 500)
+	require.ErrorIs(t, err, ErrBatchCheckSerialNumber)
+}
+
+func TestNewPOPEntry(t *testing.T) {
+	m, err := ParseMICR("T231380104T 744D5678D99U 123456789")
+	require.NoError(t, err)
+
+	ed, err := NewPOPEntry(m, 25000, "PHIL", "PA")
+	require.NoError(t, err)
+	require.Equal(t, "123456789", ed.POPCheckSerialNumberField())
+	require.Equal(t, "PHIL", ed.POPTerminalCityField())
+	require.Equal(t, "PA", ed.POPTerminalStateField())
+
+	ed.SetReceivingCompany("ABC Company")
+	ed.SetTraceNumber("12104288", 1)
+	batch := NewBatchPOP(mockBatchPOPHeader())
+	batch.AddEntry(ed)
+	require.NoError(t, batch.Create())
+
+	m.CheckSerialNumber = "1234567890"
+	_, err = NewPOPEntry(m, 25000, "PHIL", "PA")
+	require.ErrorIs(t, err, ErrInvalidMICR)
+}
@@ -0,0 +1,97 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OffsetTable chooses the Offset applied to each Batch when balancing a File.
+//
+// A batch's CompanyIdentification is checked first, then its ODFIIdentification,
+// and finally the Default offset is used. Batches without a matching Offset are left unbalanced.
+type OffsetTable struct {
+	// ByCompanyIdentification maps a BatchHeader CompanyIdentification to its offset account
+	ByCompanyIdentification map[string]*Offset `json:"byCompanyIdentification,omitempty"`
+	// ByODFI maps a BatchHeader ODFIIdentification (8 digits) to its offset account
+	ByODFI map[string]*Offset `json:"byODFI,omitempty"`
+	// Default is used for batches which match neither of the other tables
+	Default *Offset `json:"default,omitempty"`
+}
+
+// OffsetFor returns the Offset configured for a BatchHeader, or nil if none applies.
+func (t *OffsetTable) OffsetFor(bh *BatchHeader) *Offset {
+	if t == nil || bh == nil {
+		return nil
+	}
+	if off, exists := t.ByCompanyIdentification[strings.TrimSpace(bh.CompanyIdentification)]; exists && off != nil {
+		return off
+	}
+	if off, exists := t.ByODFI[aba8(bh.ODFIIdentification)]; exists && off != nil {
+		return off
+	}
+	return t.Default
+}
+
+// Balance applies offsets from the OffsetTable to each Batch in the File, re-creates the
+// batches (to tabulate offset records) and then re-creates the File.
+//
+// Offset records previously added to a batch are replaced, and batches which no longer have an
+// Offset in the table have their offset records removed. ADV and IAT batches are not balanced.
+func (f *File) Balance(table *OffsetTable) error {
+	if table == nil {
+		return fmt.Errorf("balance: nil %T", table)
+	}
+	for i := range f.Batches {
+		b := f.Batches[i]
+		if b.GetHeader().StandardEntryClassCode == ADV {
+			continue
+		}
+		// offsets read from Nacha files post to the previous account and aren't marked
+		off := table.OffsetFor(b.GetHeader())
+		if ob, ok := b.(offsetBatcher); ok {
+			ob.removeOffsets(ob.currentOffset(), off)
+		}
+		if off != nil {
+			b.WithOffset(off)
+		}
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("balance batch %d: %w", b.GetHeader().BatchNumber, err)
+		}
+	}
+	return f.Create()
+}
+
+// RemoveOffsets removes offset records from every Batch in the File and re-creates
+// the batches and File without them.
+func (f *File) RemoveOffsets() error {
+	for i := range f.Batches {
+		b := f.Batches[i]
+		if b.GetHeader().StandardEntryClassCode == ADV {
+			continue
+		}
+		if ob, ok := b.(offsetBatcher); ok {
+			ob.removeOffsets()
+		}
+		if err := b.Create(); err != nil {
+			return fmt.Errorf("remove offsets batch %d: %w", b.GetHeader().BatchNumber, err)
+		}
+	}
+	return f.Create()
+}
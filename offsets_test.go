@@ -0,0 +1,216 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockOffsetTableFile(t *testing.T) *File {
+	t.Helper()
+
+	file := mockFilePPD(t)
+
+	bh := mockBatchPPDHeader()
+	bh.CompanyIdentification = "987654321"
+	bh.ODFIIdentification = "23138010"
+	bh.ServiceClassCode = MixedDebitsAndCredits
+	batch := NewBatchPPD(bh)
+	ed := mockPPDEntryDetail()
+	ed.TransactionCode = CheckingDebit
+	ed.TraceNumber = "231380100000001"
+	ed.Amount = 2500
+	batch.AddEntry(ed)
+	require.NoError(t, batch.Create())
+	file.AddBatch(batch)
+
+	file.Batches[0].GetHeader().ServiceClassCode = MixedDebitsAndCredits
+	require.NoError(t, file.Batches[0].Create())
+	require.NoError(t, file.Create())
+	return file
+}
+
+func TestOffsetTable__OffsetFor(t *testing.T) {
+	company := &Offset{RoutingNumber: "121042882", AccountNumber: "1", AccountType: OffsetChecking}
+	odfi := &Offset{RoutingNumber: "231380104", AccountNumber: "2", AccountType: OffsetGeneralLedger}
+	fallback := &Offset{RoutingNumber: "121042882", AccountNumber: "3", AccountType: OffsetLoan}
+
+	table := &OffsetTable{
+		ByCompanyIdentification: map[string]*Offset{"121042882": company},
+		ByODFI:                  map[string]*Offset{"23138010": odfi},
+	}
+
+	bh := mockBatchPPDHeader()
+	require.Equal(t, company, table.OffsetFor(bh))
+
+	bh.CompanyIdentification = "987654321"
+	require.Nil(t, table.OffsetFor(bh))
+
+	bh.ODFIIdentification = "23138010"
+	require.Equal(t, odfi, table.OffsetFor(bh))
+
+	bh.ODFIIdentification = "12104288"
+	table.Default = fallback
+	require.Equal(t, fallback, table.OffsetFor(bh))
+
+	var nilTable *OffsetTable
+	require.Nil(t, nilTable.OffsetFor(bh))
+}
+
+func TestFile__Balance(t *testing.T) {
+	file := mockOffsetTableFile(t)
+
+	table := &OffsetTable{
+		ByCompanyIdentification: map[string]*Offset{
+			"121042882": {RoutingNumber: "121042882", AccountNumber: "123456789", AccountType: OffsetGeneralLedger, Description: "GL"},
+		},
+		ByODFI: map[string]*Offset{
+			"23138010": {RoutingNumber: "231380104", AccountNumber: "987654321", AccountType: OffsetLoan, Description: "LOAN"},
+		},
+	}
+	require.NoError(t, file.Balance(table))
+	require.NoError(t, file.Validate())
+
+	// first batch is a credit, so its offset is a GL debit
+	entries := file.Batches[0].GetEntries()
+	require.Len(t, entries, 2)
+	require.True(t, entries[1].Offset)
+	require.Equal(t, GLDebit, entries[1].TransactionCode)
+	require.Equal(t, "12104288", entries[1].RDFIIdentification)
+	require.Equal(t, entries[0].Amount, entries[1].Amount)
+
+	// second batch is a debit, so its offset is a loan credit
+	entries = file.Batches[1].GetEntries()
+	require.Len(t, entries, 2)
+	require.True(t, entries[1].Offset)
+	require.Equal(t, LoanCredit, entries[1].TransactionCode)
+	require.Equal(t, "23138010", entries[1].RDFIIdentification)
+
+	require.Equal(t, file.Control.TotalDebitEntryDollarAmountInFile, file.Control.TotalCreditEntryDollarAmountInFile)
+
+	// re-balancing against a different account replaces the offsets
+	table.ByODFI["23138010"] = &Offset{RoutingNumber: "231380104", AccountNumber: "5555", AccountType: OffsetSavings}
+	require.NoError(t, file.Balance(table))
+
+	entries = file.Batches[1].GetEntries()
+	require.Len(t, entries, 2)
+	require.Equal(t, SavingsCredit, entries[1].TransactionCode)
+	require.Equal(t, "5555", entries[1].DFIAccountNumber)
+
+	// dropping the ODFI entry removes that batch's offset
+	delete(table.ByODFI, "23138010")
+	require.NoError(t, file.Balance(table))
+	require.Len(t, file.Batches[0].GetEntries(), 2)
+	require.Len(t, file.Batches[1].GetEntries(), 1)
+
+	require.Error(t, file.Balance(nil))
+}
+
+func TestFile__RemoveOffsets(t *testing.T) {
+	file := mockOffsetTableFile(t)
+	credits := file.Control.TotalCreditEntryDollarAmountInFile
+	debits := file.Control.TotalDebitEntryDollarAmountInFile
+
+	require.NoError(t, file.Balance(&OffsetTable{
+		Default: &Offset{RoutingNumber: "121042882", AccountNumber: "123456789", AccountType: OffsetChecking},
+	}))
+	require.Equal(t, 4, file.Control.EntryAddendaCount)
+
+	require.NoError(t, file.RemoveOffsets())
+	require.Equal(t, 2, file.Control.EntryAddendaCount)
+	require.Equal(t, credits, file.Control.TotalCreditEntryDollarAmountInFile)
+	require.Equal(t, debits, file.Control.TotalDebitEntryDollarAmountInFile)
+	for _, b := range file.Batches {
+		for _, ed := range b.GetEntries() {
+			require.False(t, ed.Offset)
+		}
+	}
+}
+
+func TestFile__RebalanceReadFile(t *testing.T) {
+	file := mockOffsetTableFile(t)
+	require.NoError(t, file.Balance(&OffsetTable{
+		Default: &Offset{RoutingNumber: "121042882", AccountNumber: "111", AccountType: OffsetChecking},
+	}))
+
+	// Nacha text doesn't carry the offset marker or the batch's Offset
+	bs := writeFileBytes(t, file)
+	read, err := NewReader(bytes.NewReader(bs)).Read()
+	require.NoError(t, err)
+	require.Equal(t, 4, read.Control.EntryAddendaCount)
+
+	require.NoError(t, read.Balance(&OffsetTable{
+		Default: &Offset{RoutingNumber: "231380104", AccountNumber: "222", AccountType: OffsetSavings},
+	}))
+	require.NoError(t, read.Validate())
+	require.Equal(t, 4, read.Control.EntryAddendaCount)
+	require.Equal(t, read.Control.TotalDebitEntryDollarAmountInFile, read.Control.TotalCreditEntryDollarAmountInFile)
+	for _, b := range read.Batches {
+		entries := b.GetEntries()
+		require.Len(t, entries, 2)
+		require.Equal(t, "222", entries[1].DFIAccountNumber)
+	}
+
+	read, err = NewReader(bytes.NewReader(bs)).Read()
+	require.NoError(t, err)
+	require.NoError(t, read.RemoveOffsets())
+	require.Equal(t, 2, read.Control.EntryAddendaCount)
+	for _, b := range read.Batches {
+		require.Len(t, b.GetEntries(), 1)
+		require.NotEqual(t, "OFFSET", b.GetEntries()[0].IndividualName)
+	}
+}
+
+func TestBatch__OffsetEntriesByName(t *testing.T) {
+	off := &Offset{RoutingNumber: "121042882", AccountNumber: "123456789", AccountType: OffsetChecking}
+
+	bh := mockBatchPPDHeader()
+	bh.ServiceClassCode = MixedDebitsAndCredits
+	b := NewBatchPPD(bh)
+
+	// a receiver which happens to be named OFFSET is not an offset record
+	ed := mockPPDEntryDetail()
+	ed.IndividualName = "OFFSET"
+	b.AddEntry(ed)
+	b.WithOffset(off)
+	require.NoError(t, b.Create())
+	require.Len(t, b.Entries, 2)
+
+	// offsets read back without their marker still match the batch's offset account
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(b))
+	bs := bytes.ReplaceAll(buf.Bytes(), []byte(`,"offset":true`), nil)
+	var read Batch
+	require.NoError(t, json.Unmarshal(bs, &read))
+	require.Len(t, read.Entries, 2)
+	require.False(t, read.Entries[1].Offset)
+
+	require.NoError(t, read.build())
+	require.NoError(t, read.upsertOffsets())
+	require.Len(t, read.Entries, 2)
+	require.Equal(t, "OFFSET", read.Entries[0].IndividualName)
+	require.True(t, read.Entries[1].Offset)
+
+	read.RemoveOffsets()
+	require.Len(t, read.Entries, 1)
+	require.Nil(t, read.offset)
+}
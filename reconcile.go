@@ -0,0 +1,346 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OriginatedEntry is what a Reconciler remembers about an entry from an outbound file.
+type OriginatedEntry struct {
+	TraceNumber            string `json:"traceNumber"`
+	TransactionCode        int    `json:"transactionCode"`
+	Amount                 int    `json:"amount"`
+	RDFIIdentification     string `json:"RDFIIdentification"`
+	DFIAccountNumber       string `json:"DFIAccountNumber"`
+	IndividualName         string `json:"individualName,omitempty"`
+	StandardEntryClassCode string `json:"standardEntryClassCode"`
+	CompanyIdentification  string `json:"companyIdentification"`
+	EffectiveEntryDate     string `json:"effectiveEntryDate"`
+	// FileID is the ID of the outbound File, when it has one.
+	FileID string `json:"fileID,omitempty"`
+}
+
+// ReconciliationItem is an incoming return or Notification of Change and the entry it refers to.
+type ReconciliationItem struct {
+	// TraceNumber of the incoming return or NOC entry
+	TraceNumber string `json:"traceNumber"`
+	// OriginalTrace from the entry's Addenda99 or Addenda98 record
+	OriginalTrace string `json:"originalTrace"`
+	// Category is CategoryReturn, CategoryNOC, CategoryDishonoredReturn or CategoryDishonoredReturnContested
+	Category string `json:"category"`
+	// Code is the ReturnCode, ChangeCode, DishonoredReturnReasonCode or ContestedReturnCode
+	Code   string `json:"code"`
+	Amount int    `json:"amount"`
+
+	// Original is the outbound entry, nil when the item is unmatched
+	Original *OriginatedEntry `json:"original,omitempty"`
+}
+
+// ReconciliationReport is the result of Reconciler.Reconcile.
+type ReconciliationReport struct {
+	// Matched items have an outbound entry with their OriginalTrace and, except for NOCs, the same amount.
+	Matched []ReconciliationItem `json:"matched"`
+	// Unmatched items don't have an outbound entry in the index.
+	Unmatched []ReconciliationItem `json:"unmatched"`
+	// AmountMismatched items are returns, dishonored returns and contested dishonored returns whose
+	// amount differs from their outbound entry.
+	AmountMismatched []ReconciliationItem `json:"amountMismatched"`
+}
+
+// Reconciler matches incoming returns and Notifications of Change to the entries they refer to.
+//
+// Outbound files are indexed by trace number with AddOutbound. The index can be written with
+// WriteIndex and read back later with ReadIndex so outbound files only need to be read once.
+// A Reconciler is safe for concurrent use.
+type Reconciler struct {
+	mu      sync.RWMutex
+	entries map[string][]*OriginatedEntry
+}
+
+// NewReconciler returns a Reconciler with an empty index.
+func NewReconciler() *Reconciler {
+	return &Reconciler{
+		entries: make(map[string][]*OriginatedEntry),
+	}
+}
+
+// Len returns how many outbound entries are indexed.
+func (r *Reconciler) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n int
+	for _, entries := range r.entries {
+		n += len(entries)
+	}
+	return n
+}
+
+// AddOutbound indexes every entry of files by its trace number. The trace number includes the
+// ODFI's routing number, but sequences are often reused across days and files, so every entry
+// sharing a trace number is kept. Adding an identical entry again is ignored.
+func (r *Reconciler) AddOutbound(files ...*File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		for _, b := range file.Batches {
+			bh := b.GetHeader()
+			for _, ed := range b.GetEntries() {
+				r.add(&OriginatedEntry{
+					TraceNumber:            ed.TraceNumberField(),
+					TransactionCode:        ed.TransactionCode,
+					Amount:                 ed.Amount,
+					RDFIIdentification:     ed.RDFIIdentification,
+					DFIAccountNumber:       ed.DFIAccountNumber,
+					IndividualName:         ed.IndividualName,
+					StandardEntryClassCode: bh.StandardEntryClassCode,
+					CompanyIdentification:  bh.CompanyIdentification,
+					EffectiveEntryDate:     bh.EffectiveEntryDate,
+					FileID:                 file.ID,
+				})
+			}
+		}
+		for i := range file.IATBatches {
+			bh := file.IATBatches[i].GetHeader()
+			for _, ed := range file.IATBatches[i].Entries {
+				original := &OriginatedEntry{
+					TraceNumber:            ed.TraceNumberField(),
+					TransactionCode:        ed.TransactionCode,
+					Amount:                 ed.Amount,
+					RDFIIdentification:     ed.RDFIIdentification,
+					DFIAccountNumber:       ed.DFIAccountNumber,
+					StandardEntryClassCode: bh.StandardEntryClassCode,
+					CompanyIdentification:  bh.OriginatorIdentification,
+					EffectiveEntryDate:     bh.EffectiveEntryDate,
+					FileID:                 file.ID,
+				}
+				if ed.Addenda10 != nil {
+					original.IndividualName = ed.Addenda10.Name
+				}
+				r.add(original)
+			}
+		}
+	}
+}
+
+// add indexes ed unless an identical entry is already indexed
+func (r *Reconciler) add(ed *OriginatedEntry) {
+	for _, existing := range r.entries[ed.TraceNumber] {
+		if *existing == *ed {
+			return
+		}
+	}
+	r.entries[ed.TraceNumber] = append(r.entries[ed.TraceNumber], ed)
+}
+
+// Prune removes outbound entries with an EffectiveEntryDate before the given date and returns
+// how many were removed. Entries without a parsable EffectiveEntryDate are kept.
+func (r *Reconciler) Prune(before time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := before.Format("060102")
+	var removed int
+	for trace, entries := range r.entries {
+		kept := entries[:0]
+		for _, ed := range entries {
+			if _, err := time.Parse("060102", ed.EffectiveEntryDate); err == nil && ed.EffectiveEntryDate < cutoff {
+				removed++
+				continue
+			}
+			kept = append(kept, ed)
+		}
+		if len(kept) == 0 {
+			delete(r.entries, trace)
+		} else {
+			r.entries[trace] = kept
+		}
+	}
+	return removed
+}
+
+// Reconcile matches the returns, Notifications of Change, dishonored returns and contested dishonored
+// returns in files against the indexed outbound entries by the original trace number of their Addenda99,
+// Addenda98, Addenda99Dishonored or Addenda99Contested record. Forward entries are ignored.
+//
+// When several outbound entries share the original trace number only those sent to the item's original
+// RDFI are considered. Of those an entry with the same amount is preferred, then the latest entry
+// effective on or before the item's batch.
+func (r *Reconciler) Reconcile(files ...*File) *ReconciliationReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := &ReconciliationReport{}
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		for _, b := range file.Batches {
+			date := b.GetHeader().EffectiveEntryDate
+			for _, ed := range b.GetEntries() {
+				item, originalDFI := newReconciliationItem(ed.TraceNumberField(), ed.Amount, ed.Addenda98, ed.Addenda99, ed.Addenda99Dishonored, ed.Addenda99Contested)
+				if item != nil {
+					r.reconcile(report, *item, originalDFI, date)
+				}
+			}
+		}
+		for i := range file.IATBatches {
+			date := file.IATBatches[i].Header.EffectiveEntryDate
+			for _, ed := range file.IATBatches[i].Entries {
+				item, originalDFI := newReconciliationItem(ed.TraceNumberField(), ed.Amount, ed.Addenda98, ed.Addenda99, nil, nil)
+				if item != nil {
+					r.reconcile(report, *item, originalDFI, date)
+				}
+			}
+		}
+	}
+	return report
+}
+
+// newReconciliationItem returns the item for an incoming entry and the RDFI of the entry it refers to,
+// or nil for forward entries.
+func newReconciliationItem(trace string, amount int, addenda98 *Addenda98, addenda99 *Addenda99, dishonored *Addenda99Dishonored, contested *Addenda99Contested) (*ReconciliationItem, string) {
+	item := &ReconciliationItem{
+		TraceNumber: trace,
+		Amount:      amount,
+	}
+	switch {
+	case addenda99 != nil:
+		item.Category, item.Code = CategoryReturn, addenda99.ReturnCode
+		item.OriginalTrace = addenda99.OriginalTraceField()
+		return item, addenda99.OriginalDFI
+	case addenda98 != nil:
+		item.Category, item.Code = CategoryNOC, addenda98.ChangeCode
+		item.OriginalTrace = addenda98.OriginalTraceField()
+		return item, addenda98.OriginalDFI
+	case dishonored != nil:
+		item.Category, item.Code = CategoryDishonoredReturn, dishonored.DishonoredReturnReasonCode
+		item.OriginalTrace = dishonored.OriginalEntryTraceNumberField()
+		return item, dishonored.OriginalReceivingDFIIdentification
+	case contested != nil:
+		item.Category, item.Code = CategoryDishonoredReturnContested, contested.ContestedReturnCode
+		item.OriginalTrace = contested.OriginalEntryTraceNumberField()
+		return item, contested.OriginalReceivingDFIIdentification
+	}
+	return nil, ""
+}
+
+func (r *Reconciler) reconcile(report *ReconciliationReport, item ReconciliationItem, originalDFI, date string) {
+	original := r.findOriginal(item, strings.TrimSpace(originalDFI), date)
+	if original == nil {
+		report.Unmatched = append(report.Unmatched, item)
+		return
+	}
+	copied := *original
+	item.Original = &copied
+
+	if item.Category != CategoryNOC && item.Amount != original.Amount {
+		report.AmountMismatched = append(report.AmountMismatched, item)
+		return
+	}
+	report.Matched = append(report.Matched, item)
+}
+
+// findOriginal picks the outbound entry item refers to among those sharing its OriginalTrace
+func (r *Reconciler) findOriginal(item ReconciliationItem, originalDFI, date string) *OriginatedEntry {
+	var best *OriginatedEntry
+	better := func(ed *OriginatedEntry) bool {
+		if item.Category != CategoryNOC {
+			if sameAmount := ed.Amount == item.Amount; sameAmount != (best.Amount == item.Amount) {
+				return sameAmount
+			}
+		}
+		if before := ed.EffectiveEntryDate <= date; before != (best.EffectiveEntryDate <= date) {
+			return before
+		}
+		return ed.EffectiveEntryDate > best.EffectiveEntryDate
+	}
+	for _, ed := range r.entries[item.OriginalTrace] {
+		if originalDFI != "" && ed.RDFIIdentification != originalDFI {
+			continue
+		}
+		if best == nil || better(ed) {
+			best = ed
+		}
+	}
+	return best
+}
+
+// reconcilerIndex is the persisted format of a Reconciler's index
+type reconcilerIndex struct {
+	Entries []*OriginatedEntry `json:"entries"`
+}
+
+// WriteIndex writes the indexed outbound entries to w as JSON, sorted by trace number.
+func (r *Reconciler) WriteIndex(w io.Writer) error {
+	r.mu.RLock()
+	index := reconcilerIndex{
+		Entries: make([]*OriginatedEntry, 0, len(r.entries)),
+	}
+	for _, entries := range r.entries {
+		index.Entries = append(index.Entries, entries...)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(index.Entries, func(i, j int) bool {
+		a, b := index.Entries[i], index.Entries[j]
+		switch {
+		case a.TraceNumber != b.TraceNumber:
+			return a.TraceNumber < b.TraceNumber
+		case a.EffectiveEntryDate != b.EffectiveEntryDate:
+			return a.EffectiveEntryDate < b.EffectiveEntryDate
+		case a.RDFIIdentification != b.RDFIIdentification:
+			return a.RDFIIdentification < b.RDFIIdentification
+		case a.FileID != b.FileID:
+			return a.FileID < b.FileID
+		}
+		return a.Amount < b.Amount
+	})
+	if err := json.NewEncoder(w).Encode(index); err != nil {
+		return fmt.Errorf("writing reconciler index: %w", err)
+	}
+	return nil
+}
+
+// ReadIndex adds the outbound entries written by WriteIndex to the Reconciler's index.
+func (r *Reconciler) ReadIndex(rd io.Reader) error {
+	var index reconcilerIndex
+	if err := json.NewDecoder(rd).Decode(&index); err != nil {
+		return fmt.Errorf("reading reconciler index: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ed := range index.Entries {
+		if ed != nil && ed.TraceNumber != "" {
+			r.add(ed)
+		}
+	}
+	return nil
+}
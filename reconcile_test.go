@@ -0,0 +1,212 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciler(t *testing.T) {
+	outbound, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	outbound.ID = "outbound"
+	first, second := outbound.Batches[0].GetEntries()[0], outbound.Batches[0].GetEntries()[1]
+
+	// the first return matches, the second has a different amount
+	returns, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+	matching, mismatched := returns.Batches[0].GetEntries()[0], returns.Batches[1].GetEntries()[0]
+	matching.Amount = first.Amount
+	matching.Addenda99.OriginalTrace = first.TraceNumber
+	matching.Addenda99.OriginalDFI = first.RDFIIdentification
+	mismatched.Amount = second.Amount + 1
+	mismatched.Addenda99.OriginalTrace = second.TraceNumber
+	mismatched.Addenda99.OriginalDFI = second.RDFIIdentification
+
+	cor, err := readACHFilepath(filepath.Join("test", "testdata", "cor-example.ach"))
+	require.NoError(t, err)
+	unknown := cor.Batches[0].GetEntries()[0].Addenda98.OriginalTrace
+
+	r := NewReconciler()
+	r.AddOutbound(outbound, nil)
+	require.Equal(t, 6, r.Len())
+
+	report := r.Reconcile(returns, cor, outbound)
+	require.Len(t, report.Matched, 1)
+	require.Len(t, report.AmountMismatched, 1)
+	require.Len(t, report.Unmatched, 1)
+
+	matched := report.Matched[0]
+	require.Equal(t, CategoryReturn, matched.Category)
+	require.Equal(t, "R01", matched.Code)
+	require.Equal(t, first.TraceNumber, matched.OriginalTrace)
+	require.Equal(t, first.IndividualName, matched.Original.IndividualName)
+	require.Equal(t, WEB, matched.Original.StandardEntryClassCode)
+	require.Equal(t, "outbound", matched.Original.FileID)
+
+	require.Equal(t, second.Amount, report.AmountMismatched[0].Original.Amount)
+
+	require.Equal(t, CategoryNOC, report.Unmatched[0].Category)
+	require.Equal(t, unknown, report.Unmatched[0].OriginalTrace)
+	require.Nil(t, report.Unmatched[0].Original)
+
+	// NOCs with zero amounts still match
+	cor.Batches[0].GetEntries()[0].Addenda98.OriginalTrace = second.TraceNumber
+	cor.Batches[0].GetEntries()[0].Addenda98.OriginalDFI = second.RDFIIdentification
+	report = r.Reconcile(cor)
+	require.Len(t, report.Matched, 1)
+	require.Equal(t, CategoryNOC, report.Matched[0].Category)
+}
+
+func TestReconciler__Index(t *testing.T) {
+	outbound, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	iat, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+
+	r := NewReconciler()
+	r.AddOutbound(outbound, iat)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteIndex(&buf))
+
+	read := NewReconciler()
+	require.NoError(t, read.ReadIndex(&buf))
+	require.Equal(t, r.Len(), read.Len())
+
+	// trace numbers repeat across this file's batches, so both entries are indexed
+	first, last := iat.IATBatches[0].Entries[0], iat.IATBatches[1].Entries[0]
+	require.Equal(t, first.TraceNumber, last.TraceNumber)
+	require.Len(t, read.entries[last.TraceNumber], 2)
+
+	// reading the index again doesn't duplicate them
+	var written bytes.Buffer
+	require.NoError(t, read.WriteIndex(&written))
+	require.NoError(t, read.ReadIndex(&written))
+	require.Equal(t, r.Len(), read.Len())
+
+	// a return picks the entry with its amount
+	returns, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+	ret := returns.Batches[0].GetEntries()[0]
+	ret.Amount = last.Amount
+	ret.Addenda99.OriginalTrace = last.TraceNumber
+	ret.Addenda99.OriginalDFI = last.RDFIIdentification
+
+	report := read.Reconcile(returns)
+	require.Len(t, report.Matched, 1)
+	require.Equal(t, last.Addenda10.Name, report.Matched[0].Original.IndividualName)
+	require.Equal(t, last.TransactionCode, report.Matched[0].Original.TransactionCode)
+
+	// but not one sent to another RDFI
+	ret.Addenda99.OriginalDFI = "99999999"
+	report = read.Reconcile(returns)
+	require.Empty(t, report.Matched)
+	require.Len(t, report.Unmatched, 2)
+
+	require.Error(t, read.ReadIndex(bytes.NewReader([]byte("{"))))
+}
+
+func TestReconciler__RepeatedTraceNumbers(t *testing.T) {
+	readOutbound := func(id, date string) *File {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+		require.NoError(t, err)
+		file.ID = id
+		file.Batches[0].GetHeader().EffectiveEntryDate = date
+		return file
+	}
+	r := NewReconciler()
+	r.AddOutbound(readOutbound("january", "260105"), readOutbound("february", "260205"))
+	r.AddOutbound(readOutbound("january", "260105"))
+	outbound := readOutbound("", "")
+	require.Equal(t, 2*countTraceNumbers(outbound), r.Len())
+
+	returns, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+	original := outbound.Batches[0].GetEntries()[0]
+	ed := returns.Batches[0].GetEntries()[0]
+	ed.Amount = original.Amount
+	ed.Addenda99.OriginalTrace = original.TraceNumber
+	ed.Addenda99.OriginalDFI = original.RDFIIdentification
+
+	// the latest entry effective on or before the return is picked
+	returns.Batches[0].GetHeader().EffectiveEntryDate = "260110"
+	report := r.Reconcile(returns)
+	require.Len(t, report.Matched, 1)
+	require.Equal(t, "january", report.Matched[0].Original.FileID)
+
+	returns.Batches[0].GetHeader().EffectiveEntryDate = "260210"
+	report = r.Reconcile(returns)
+	require.Len(t, report.Matched, 1)
+	require.Equal(t, "february", report.Matched[0].Original.FileID)
+}
+
+func TestReconciler__DishonoredReturns(t *testing.T) {
+	outbound, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	original := outbound.Batches[0].GetEntries()[0]
+
+	r := NewReconciler()
+	r.AddOutbound(outbound)
+
+	returns, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+	dishonored, contested := returns.Batches[0].GetEntries()[0], returns.Batches[1].GetEntries()[0]
+
+	dishonored.Amount = original.Amount
+	dishonored.Addenda99 = nil
+	dishonored.Addenda99Dishonored = NewAddenda99Dishonored()
+	dishonored.Addenda99Dishonored.DishonoredReturnReasonCode = "R69"
+	dishonored.Addenda99Dishonored.OriginalEntryTraceNumber = original.TraceNumber
+	dishonored.Addenda99Dishonored.OriginalReceivingDFIIdentification = original.RDFIIdentification
+
+	contested.Amount = original.Amount
+	contested.Addenda99 = nil
+	contested.Addenda99Contested = NewAddenda99Contested()
+	contested.Addenda99Contested.ContestedReturnCode = "R71"
+	contested.Addenda99Contested.OriginalEntryTraceNumber = original.TraceNumber
+	contested.Addenda99Contested.OriginalReceivingDFIIdentification = original.RDFIIdentification
+
+	report := r.Reconcile(returns)
+	require.Len(t, report.Matched, 2)
+	require.Equal(t, CategoryDishonoredReturn, report.Matched[0].Category)
+	require.Equal(t, "R69", report.Matched[0].Code)
+	require.Equal(t, CategoryDishonoredReturnContested, report.Matched[1].Category)
+	require.Equal(t, "R71", report.Matched[1].Code)
+	require.Equal(t, original.TraceNumber, report.Matched[1].Original.TraceNumber)
+}
+
+func TestReconciler__Prune(t *testing.T) {
+	outbound, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	outbound.Batches[0].GetHeader().EffectiveEntryDate = "260101"
+	outbound.Batches[1].GetHeader().EffectiveEntryDate = "260301"
+	outbound.Batches[2].GetHeader().EffectiveEntryDate = ""
+
+	r := NewReconciler()
+	r.AddOutbound(outbound)
+
+	removed := r.Prune(time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, len(outbound.Batches[0].GetEntries()), removed)
+	require.Equal(t, len(outbound.Batches[1].GetEntries())+len(outbound.Batches[2].GetEntries()), r.Len())
+}
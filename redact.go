@@ -0,0 +1,406 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/moov-io/base"
+)
+
+// RedactionPolicy chooses which fields Redact replaces with pseudonyms.
+type RedactionPolicy struct {
+	// Key seeds the pseudonyms. Files redacted with the same Key replace equal values with
+	// equal pseudonyms. When Key is empty a random key is used for each call to Redact.
+	Key string `json:"key"`
+
+	// Names replaces IndividualName on entries, CompanyName on batch headers and corrected names.
+	Names bool `json:"names"`
+	// AccountNumbers replaces DFIAccountNumber on entries and corrected account numbers.
+	AccountNumbers bool `json:"accountNumbers"`
+	// IdentificationNumbers replaces IdentificationNumber on entries, CompanyIdentification on batch
+	// headers, OriginatorIdentification on IAT batch headers and corrected identification numbers.
+	// The terminal state of POP entries and the card expiration date of SHR entries are kept.
+	IdentificationNumbers bool `json:"identificationNumbers"`
+	// RoutingNumbers replaces the RDFI routing number on entries, corrected routing numbers, the OriginalDFI
+	// on Addenda98 and Addenda99 records, and the original RDFI and return trace number prefix on
+	// Addenda99Dishonored and Addenda99Contested records. Check digits are recalculated.
+	RoutingNumbers bool `json:"routingNumbers"`
+	// AddendaText replaces free text in Addenda02, Addenda05, Addenda17, Addenda99 and Addenda99Dishonored
+	// records, and the CorrectedData of change codes Redact doesn't parse.
+	AddendaText bool `json:"addendaText"`
+	// IATParties replaces the originator and receiver names, addresses and identification numbers
+	// found in IAT Addenda10, Addenda11, Addenda12, Addenda15 and Addenda16 records, the ODFI and
+	// RDFI names in Addenda13 and Addenda14 records and the foreign correspondent bank names and
+	// identification numbers in Addenda18 records.
+	IATParties bool `json:"iatParties"`
+}
+
+// Redact returns a copy of file with the fields chosen by policy replaced by pseudonyms.
+// A nil policy redacts every field with a random key.
+//
+// Letters are replaced with letters and digits with digits so each field keeps its length and
+// format. The same value is always replaced with the same pseudonym for a given Key. Batches
+// and the File are re-created so check digits, entry hashes and controls match the new values.
+// The original file is not modified.
+func Redact(file *File, policy *RedactionPolicy) (*File, error) {
+	if file == nil {
+		return nil, errors.New("redact: nil File")
+	}
+	if policy == nil {
+		policy = &RedactionPolicy{
+			Names:                 true,
+			AccountNumbers:        true,
+			IdentificationNumbers: true,
+			RoutingNumbers:        true,
+			AddendaText:           true,
+			IATParties:            true,
+		}
+	}
+
+	out, err := copyFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+
+	r := newRedactor(policy)
+	for _, b := range out.Batches {
+		r.batchHeader(b.GetHeader())
+		for _, ed := range b.GetEntries() {
+			r.entry(b.GetHeader().StandardEntryClassCode, ed)
+		}
+		for _, ed := range b.GetADVEntries() {
+			r.advEntry(ed)
+		}
+		if err := b.Create(); err != nil {
+			return nil, fmt.Errorf("redact batch %d: %w", b.GetHeader().BatchNumber, err)
+		}
+	}
+	for i := range out.IATBatches {
+		b := &out.IATBatches[i]
+		if policy.IdentificationNumbers {
+			b.Header.OriginatorIdentification = r.mask("id", b.Header.OriginatorIdentification)
+		}
+		for _, ed := range b.Entries {
+			r.iatEntry(ed)
+		}
+		if err := b.Create(); err != nil {
+			return nil, fmt.Errorf("redact IAT batch %d: %w", b.Header.BatchNumber, err)
+		}
+	}
+	if err := out.Create(); err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+	if err := out.Validate(); err != nil {
+		return nil, fmt.Errorf("redact: %w", err)
+	}
+	return out, nil
+}
+
+// copyFile returns a deep copy of file by writing and reading it in the Nacha format.
+func copyFile(file *File) (*File, error) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.BypassValidation = true
+	if err := w.Write(file); err != nil {
+		return nil, err
+	}
+
+	r := NewReader(&buf)
+	r.SetValidation(file.GetValidation())
+	out, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	out.SetValidation(file.GetValidation())
+	return &out, nil
+}
+
+type redactor struct {
+	policy *RedactionPolicy
+	key    []byte
+}
+
+func newRedactor(policy *RedactionPolicy) *redactor {
+	key := policy.Key
+	if key == "" {
+		key = base.ID()
+	}
+	return &redactor{
+		policy: policy,
+		key:    []byte(key),
+	}
+}
+
+// stream returns n pseudorandom bytes derived from the key, kind and value.
+func (r *redactor) stream(kind, value string, n int) []byte {
+	var out []byte
+	for counter := 0; len(out) < n; counter++ {
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(kind))
+		mac.Write([]byte{0})
+		mac.Write([]byte(value))
+		mac.Write([]byte{byte(counter)})
+		out = mac.Sum(out)
+	}
+	return out[:n]
+}
+
+// mask replaces each letter and digit in value with a pseudonymous letter or digit,
+// keeping spaces, punctuation and letter case.
+func (r *redactor) mask(kind, value string) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value
+	}
+	stream := r.stream(kind, trimmed, len(trimmed))
+	out := []byte(trimmed)
+	for i, c := range out {
+		switch {
+		case c >= '0' && c <= '9':
+			out[i] = '0' + stream[i]%10
+		case c >= 'A' && c <= 'Z':
+			out[i] = 'A' + stream[i]%26
+		case c >= 'a' && c <= 'z':
+			out[i] = 'a' + stream[i]%26
+		}
+	}
+	return string(out)
+}
+
+// maskField masks value like mask but keeps the spaces around it, so fixed width
+// fields keep their layout.
+func (r *redactor) maskField(kind, value string) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value
+	}
+	return strings.Replace(value, trimmed, r.mask(kind, trimmed), 1)
+}
+
+// maskPart masks one part of an asterisk delimited value, such as the city in "City*ST\".
+func (r *redactor) maskPart(kind, value string, idx int) string {
+	parts := strings.Split(value, "*")
+	if idx < len(parts) {
+		parts[idx] = r.mask(kind, parts[idx])
+	}
+	return strings.Join(parts, "*")
+}
+
+// routing returns a pseudonymous 8 digit routing number prefix which keeps the
+// Federal Reserve routing symbol (first two digits) of the original.
+func (r *redactor) routing(rdfi string) string {
+	if len(rdfi) < 8 {
+		return rdfi
+	}
+	if _, err := strconv.Atoi(rdfi[:8]); err != nil {
+		return rdfi
+	}
+	return rdfi[:2] + r.mask("routing", rdfi[:8])[2:]
+}
+
+// trace replaces the routing number prefix of a trace number sent by an RDFI
+func (r *redactor) trace(trace string) string {
+	if len(trace) < 15 {
+		return trace
+	}
+	return r.routing(trace[:8]) + trace[8:]
+}
+
+// correctedData masks the fields of a Notification of Change's CorrectedData chosen by the policy.
+// Corrected data of unsupported change codes is masked as text.
+func (r *redactor) correctedData(a *Addenda98) {
+	cd := a.ParseCorrectedData()
+	if cd == nil {
+		if r.policy.AddendaText {
+			a.CorrectedData = r.maskField("text", a.CorrectedData)
+		}
+		return
+	}
+	if r.policy.AccountNumbers {
+		cd.AccountNumber = r.mask("account", cd.AccountNumber)
+	}
+	if r.policy.RoutingNumbers && len(cd.RoutingNumber) == 9 {
+		cd.RoutingNumber = r.routing(cd.RoutingNumber)
+		cd.RoutingNumber += checkDigit(cd.RoutingNumber)
+	}
+	if r.policy.Names {
+		cd.Name = r.mask("name", cd.Name)
+	}
+	if r.policy.IdentificationNumbers {
+		cd.Identification = r.mask("id", cd.Identification)
+	}
+	a.CorrectedData = WriteCorrectionData(a.ChangeCode, cd)
+}
+
+func checkDigit(rdfi string) string {
+	return strconv.Itoa(CalculateCheckDigit(rdfi))
+}
+
+func (r *redactor) batchHeader(bh *BatchHeader) {
+	if r.policy.Names {
+		bh.CompanyName = r.mask("name", bh.CompanyName)
+	}
+	if r.policy.IdentificationNumbers {
+		bh.CompanyIdentification = r.mask("id", bh.CompanyIdentification)
+	}
+}
+
+func (r *redactor) entry(sec string, ed *EntryDetail) {
+	if r.policy.Names && !strings.EqualFold(ed.IndividualName, offsetIndividualName) {
+		switch sec {
+		case ATX, CTX:
+			ed.SetCATXReceivingCompany(r.mask("name", ed.CATXReceivingCompanyField()))
+		case TRC, TRX, XCK:
+			// IndividualName holds item and control information rather than a name
+		default:
+			ed.IndividualName = r.mask("name", ed.IndividualName)
+		}
+	}
+	if r.policy.AccountNumbers {
+		ed.DFIAccountNumber = r.mask("account", ed.DFIAccountNumber)
+	}
+	if ed.Addenda98 != nil {
+		r.correctedData(ed.Addenda98)
+	}
+	if r.policy.IdentificationNumbers {
+		id := ed.IdentificationNumberField()
+		switch sec {
+		case POP:
+			// check serial number and terminal city, keeping the terminal state
+			ed.IdentificationNumber = r.maskField("id", id[:9]) + r.maskField("address", id[9:13]) + id[13:15]
+		case SHR:
+			// document reference number, keeping the MMYY card expiration date
+			ed.IdentificationNumber = id[:4] + r.maskField("id", id[4:15])
+		default:
+			ed.IdentificationNumber = r.mask("id", ed.IdentificationNumber)
+		}
+	}
+	if r.policy.RoutingNumbers {
+		ed.RDFIIdentification = r.routing(ed.RDFIIdentification)
+		ed.CheckDigit = checkDigit(ed.RDFIIdentification)
+		if ed.Addenda98 != nil {
+			ed.Addenda98.OriginalDFI = r.routing(ed.Addenda98.OriginalDFI)
+		}
+		if ed.Addenda99 != nil {
+			ed.Addenda99.OriginalDFI = r.routing(ed.Addenda99.OriginalDFI)
+		}
+		if a := ed.Addenda99Dishonored; a != nil {
+			a.OriginalReceivingDFIIdentification = r.routing(a.OriginalReceivingDFIIdentification)
+			a.ReturnTraceNumber = r.trace(a.ReturnTraceNumberField())
+		}
+		if a := ed.Addenda99Contested; a != nil {
+			a.OriginalReceivingDFIIdentification = r.routing(a.OriginalReceivingDFIIdentification)
+			a.ReturnTraceNumber = r.trace(a.ReturnTraceNumberField())
+		}
+	}
+	if r.policy.AddendaText {
+		if a := ed.Addenda02; a != nil {
+			a.ReferenceInformationOne = r.mask("text", a.ReferenceInformationOne)
+			a.ReferenceInformationTwo = r.mask("text", a.ReferenceInformationTwo)
+			a.TerminalLocation = r.mask("text", a.TerminalLocation)
+			a.TerminalCity = r.mask("text", a.TerminalCity)
+		}
+		for _, a := range ed.Addenda05 {
+			a.PaymentRelatedInformation = r.mask("text", a.PaymentRelatedInformation)
+		}
+		if ed.Addenda99 != nil {
+			ed.Addenda99.AddendaInformation = r.mask("text", ed.Addenda99.AddendaInformation)
+		}
+		if ed.Addenda99Dishonored != nil {
+			ed.Addenda99Dishonored.AddendaInformation = r.mask("text", ed.Addenda99Dishonored.AddendaInformation)
+		}
+	}
+}
+
+func (r *redactor) advEntry(ed *ADVEntryDetail) {
+	if r.policy.Names {
+		ed.IndividualName = r.mask("name", ed.IndividualName)
+	}
+	if r.policy.AccountNumbers {
+		ed.DFIAccountNumber = r.mask("account", ed.DFIAccountNumber)
+	}
+	if r.policy.RoutingNumbers {
+		ed.RDFIIdentification = r.routing(ed.RDFIIdentification)
+		ed.CheckDigit = checkDigit(ed.RDFIIdentification)
+	}
+}
+
+func (r *redactor) iatEntry(ed *IATEntryDetail) {
+	if r.policy.AccountNumbers {
+		ed.DFIAccountNumber = r.mask("account", ed.DFIAccountNumber)
+	}
+	if ed.Addenda98 != nil {
+		r.correctedData(ed.Addenda98)
+	}
+	if r.policy.RoutingNumbers {
+		ed.RDFIIdentification = r.routing(ed.RDFIIdentification)
+		ed.CheckDigit = checkDigit(ed.RDFIIdentification)
+		if ed.Addenda98 != nil {
+			ed.Addenda98.OriginalDFI = r.routing(ed.Addenda98.OriginalDFI)
+		}
+		if ed.Addenda99 != nil {
+			ed.Addenda99.OriginalDFI = r.routing(ed.Addenda99.OriginalDFI)
+		}
+	}
+	if r.policy.IATParties {
+		if a := ed.Addenda10; a != nil {
+			a.Name = r.mask("name", a.Name)
+		}
+		if a := ed.Addenda11; a != nil {
+			a.OriginatorName = r.mask("name", a.OriginatorName)
+			a.OriginatorStreetAddress = r.mask("address", a.OriginatorStreetAddress)
+		}
+		if a := ed.Addenda12; a != nil {
+			a.OriginatorCityStateProvince = r.maskPart("address", a.OriginatorCityStateProvince, 0)
+			a.OriginatorCountryPostalCode = r.maskPart("address", a.OriginatorCountryPostalCode, 1)
+		}
+		if a := ed.Addenda15; a != nil {
+			a.ReceiverIDNumber = r.mask("id", a.ReceiverIDNumber)
+			a.ReceiverStreetAddress = r.mask("address", a.ReceiverStreetAddress)
+		}
+		if a := ed.Addenda16; a != nil {
+			a.ReceiverCityStateProvince = r.maskPart("address", a.ReceiverCityStateProvince, 0)
+			a.ReceiverCountryPostalCode = r.maskPart("address", a.ReceiverCountryPostalCode, 1)
+		}
+		if a := ed.Addenda13; a != nil {
+			a.ODFIName = r.mask("name", a.ODFIName)
+		}
+		if a := ed.Addenda14; a != nil {
+			a.RDFIName = r.mask("name", a.RDFIName)
+		}
+		for _, a := range ed.Addenda18 {
+			a.ForeignCorrespondentBankName = r.mask("name", a.ForeignCorrespondentBankName)
+			a.ForeignCorrespondentBankIDNumber = r.mask("id", a.ForeignCorrespondentBankIDNumber)
+		}
+	}
+	if r.policy.AddendaText {
+		for _, a := range ed.Addenda17 {
+			a.PaymentRelatedInformation = r.mask("text", a.PaymentRelatedInformation)
+		}
+		if ed.Addenda99 != nil {
+			ed.Addenda99.AddendaInformation = r.mask("text", ed.Addenda99.AddendaInformation)
+		}
+	}
+}
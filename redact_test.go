@@ -0,0 +1,259 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+
+	// give two entries the same receiver so we can check the pseudonyms match
+	first, second := file.Batches[0].GetEntries()[0], file.Batches[1].GetEntries()[0]
+	second.IndividualName = first.IndividualName
+	second.DFIAccountNumber = first.DFIAccountNumber
+	second.RDFIIdentification, second.CheckDigit = first.RDFIIdentification, first.CheckDigit
+	require.NoError(t, file.Batches[1].Create())
+	require.NoError(t, file.Create())
+	original := string(writeFileBytes(t, file))
+
+	policy := &RedactionPolicy{
+		Key:                   "secret",
+		Names:                 true,
+		AccountNumbers:        true,
+		IdentificationNumbers: true,
+		RoutingNumbers:        true,
+	}
+	out, err := Redact(file, policy)
+	require.NoError(t, err)
+	require.NoError(t, out.Validate())
+
+	// the input is untouched
+	require.Equal(t, original, string(writeFileBytes(t, file)))
+
+	for i, b := range out.Batches {
+		require.NotEqual(t, file.Batches[i].GetHeader().CompanyName, b.GetHeader().CompanyName)
+		require.NotEqual(t, file.Batches[i].GetHeader().CompanyIdentification, b.GetHeader().CompanyIdentification)
+
+		for j, ed := range b.GetEntries() {
+			orig := file.Batches[i].GetEntries()[j]
+			require.NotEqual(t, orig.IndividualName, ed.IndividualName)
+			require.NotEqual(t, orig.DFIAccountNumber, ed.DFIAccountNumber)
+			require.Len(t, ed.DFIAccountNumber, len(orig.DFIAccountNumber))
+			require.Equal(t, orig.RDFIIdentification[:2], ed.RDFIIdentification[:2])
+			require.NoError(t, CheckRoutingNumber(ed.RDFIIdentification+ed.CheckDigit))
+			require.Equal(t, orig.Amount, ed.Amount)
+			require.Equal(t, orig.TraceNumber, ed.TraceNumber)
+		}
+	}
+	require.Equal(t, file.Control.TotalDebitEntryDollarAmountInFile, out.Control.TotalDebitEntryDollarAmountInFile)
+	require.Equal(t, file.Control.EntryAddendaCount, out.Control.EntryAddendaCount)
+
+	// equal values get equal pseudonyms
+	a, b := out.Batches[0].GetEntries()[0], out.Batches[1].GetEntries()[0]
+	require.Equal(t, a.IndividualName, b.IndividualName)
+	require.Equal(t, a.DFIAccountNumber, b.DFIAccountNumber)
+	require.Equal(t, a.RDFIIdentification, b.RDFIIdentification)
+
+	// and do so across calls with the same key
+	again, err := Redact(file, policy)
+	require.NoError(t, err)
+	require.Equal(t, string(writeFileBytes(t, out)), string(writeFileBytes(t, again)))
+
+	policy.Key = "other"
+	other, err := Redact(file, policy)
+	require.NoError(t, err)
+	require.NotEqual(t, a.IndividualName, other.Batches[0].GetEntries()[0].IndividualName)
+}
+
+func TestRedact__Policy(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+
+	out, err := Redact(file, &RedactionPolicy{Names: true})
+	require.NoError(t, err)
+
+	orig, ed := file.Batches[0].GetEntries()[0], out.Batches[0].GetEntries()[0]
+	require.NotEqual(t, orig.IndividualName, ed.IndividualName)
+	require.Equal(t, orig.DFIAccountNumber, ed.DFIAccountNumber)
+	require.Equal(t, orig.IdentificationNumber, ed.IdentificationNumber)
+	require.Equal(t, orig.RDFIIdentification, ed.RDFIIdentification)
+	require.Equal(t, file.Control.EntryHash, out.Control.EntryHash)
+
+	_, err = Redact(nil, nil)
+	require.Error(t, err)
+}
+
+func TestRedact__IAT(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+
+	out, err := Redact(file, nil)
+	require.NoError(t, err)
+	require.NoError(t, out.Validate())
+
+	orig, ed := file.IATBatches[0].Entries[0], out.IATBatches[0].Entries[0]
+	require.NotEqual(t, orig.DFIAccountNumber, ed.DFIAccountNumber)
+	require.NotEqual(t, orig.Addenda10.Name, ed.Addenda10.Name)
+	require.NotEqual(t, orig.Addenda11.OriginatorName, ed.Addenda11.OriginatorName)
+	require.NotEqual(t, orig.Addenda15.ReceiverStreetAddress, ed.Addenda15.ReceiverStreetAddress)
+	require.NotEqual(t, orig.Addenda17[0].PaymentRelatedInformation, ed.Addenda17[0].PaymentRelatedInformation)
+	require.NoError(t, CheckRoutingNumber(ed.RDFIIdentification+ed.CheckDigit))
+
+	// state and country codes are kept
+	require.Equal(t, "PA\\", orig.Addenda12.OriginatorCityStateProvince[len(orig.Addenda12.OriginatorCityStateProvince)-3:])
+	require.Equal(t, "PA\\", ed.Addenda12.OriginatorCityStateProvince[len(ed.Addenda12.OriginatorCityStateProvince)-3:])
+	require.Equal(t, orig.Addenda16.ReceiverCountryPostalCode[:3], ed.Addenda16.ReceiverCountryPostalCode[:3])
+
+	// bank names and correspondent bank IDs are replaced
+	require.NotEqual(t, orig.Addenda13.ODFIName, ed.Addenda13.ODFIName)
+	require.NotEqual(t, orig.Addenda14.RDFIName, ed.Addenda14.RDFIName)
+	for i := range orig.Addenda18 {
+		require.NotEqual(t, orig.Addenda18[i].ForeignCorrespondentBankName, ed.Addenda18[i].ForeignCorrespondentBankName)
+		require.NotEqual(t, orig.Addenda18[i].ForeignCorrespondentBankIDNumber, ed.Addenda18[i].ForeignCorrespondentBankIDNumber)
+	}
+}
+
+func TestRedact__Returns(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+
+	out, err := Redact(file, &RedactionPolicy{Key: "secret", RoutingNumbers: true, AddendaText: true})
+	require.NoError(t, err)
+	require.NoError(t, out.Validate())
+
+	orig, ed := file.Batches[0].GetEntries()[0], out.Batches[0].GetEntries()[0]
+	require.Equal(t, orig.Addenda99.ReturnCode, ed.Addenda99.ReturnCode)
+	require.Equal(t, orig.Addenda99.OriginalTrace, ed.Addenda99.OriginalTrace)
+	require.NotEqual(t, orig.Addenda99.OriginalDFI, ed.Addenda99.OriginalDFI)
+}
+
+func TestRedact__ReadFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("test", "ach-*-read", "*.ach"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	paths = append(paths, filepath.Join("test", "testdata", "return-dishonored.ach"))
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			file, err := readACHFilepath(path)
+			require.NoError(t, err)
+
+			out, err := Redact(file, nil)
+			require.NoError(t, err)
+			require.NoError(t, out.Validate())
+		})
+	}
+
+	// generated files of every SEC code
+	for sec := range generateSpecs {
+		categories := []string{CategoryForward, CategoryReturn, CategoryNOC}
+		for _, category := range categories {
+			file, err := GenerateFile(GenerateOpts{StandardEntryClassCode: sec, Category: category, Seed: 3})
+			if err != nil {
+				continue // category isn't supported for the SEC code
+			}
+			out, err := Redact(file, nil)
+			require.NoError(t, err, "%s %s", sec, category)
+			require.NoError(t, out.Validate(), "%s %s", sec, category)
+		}
+	}
+}
+
+func TestRedact__SHR(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "ach-shr-read", "shr-debit.ach"))
+	require.NoError(t, err)
+
+	out, err := Redact(file, nil)
+	require.NoError(t, err)
+
+	orig, ed := file.Batches[0].GetEntries()[0], out.Batches[0].GetEntries()[0]
+	require.Equal(t, orig.SHRCardExpirationDateField(), ed.SHRCardExpirationDateField())
+	require.NotEqual(t, orig.SHRDocumentReferenceNumberField(), ed.SHRDocumentReferenceNumberField())
+	require.Len(t, ed.IdentificationNumberField(), 15)
+}
+
+func TestRedact__CorrectedData(t *testing.T) {
+	cases := map[string]*CorrectedData{
+		"C01": {AccountNumber: "1918171614"},
+		"C02": {RoutingNumber: "231380104"},
+		"C03": {RoutingNumber: "231380104", AccountNumber: "1918171614"},
+		"C04": {Name: "Jane Doe"},
+		"C05": {TransactionCode: 32},
+		"C06": {AccountNumber: "1918171614", TransactionCode: 32},
+		"C07": {RoutingNumber: "231380104", AccountNumber: "1918171614", TransactionCode: 32},
+		"C09": {Identification: "21345678"},
+	}
+	for code, data := range cases {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", "cor-example.ach"))
+		require.NoError(t, err)
+		a := file.Batches[0].GetEntries()[0].Addenda98
+		a.ChangeCode = code
+		a.CorrectedData = WriteCorrectionData(code, data)
+
+		out, err := Redact(file, nil)
+		require.NoError(t, err, code)
+		redacted := out.Batches[0].GetEntries()[0].Addenda98.ParseCorrectedData()
+		require.NotNil(t, redacted, code)
+
+		require.Equal(t, data.TransactionCode, redacted.TransactionCode, code)
+		if data.RoutingNumber != "" {
+			require.NoError(t, CheckRoutingNumber(redacted.RoutingNumber), code)
+			require.Equal(t, data.RoutingNumber[:2], redacted.RoutingNumber[:2], code)
+		}
+		for _, pair := range [][2]string{
+			{data.AccountNumber, redacted.AccountNumber},
+			{data.Name, redacted.Name},
+			{data.Identification, redacted.Identification},
+		} {
+			if pair[0] != "" {
+				require.Len(t, pair[1], len(pair[0]), code)
+				require.NotEqual(t, pair[0], pair[1], code)
+			}
+		}
+	}
+}
+
+func TestRedact__DishonoredReturns(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "return-dishonored.ach"))
+	require.NoError(t, err)
+
+	out, err := Redact(file, nil)
+	require.NoError(t, err)
+
+	orig, a := file.Batches[0].GetEntries()[0].Addenda99Dishonored, out.Batches[0].GetEntries()[0].Addenda99Dishonored
+	require.NotEqual(t, orig.OriginalReceivingDFIIdentification, a.OriginalReceivingDFIIdentification)
+	require.NotEqual(t, orig.ReturnTraceNumber[:8], a.ReturnTraceNumber[:8])
+	require.Equal(t, orig.ReturnTraceNumber[8:], a.ReturnTraceNumber[8:])
+	require.Equal(t, orig.DishonoredReturnReasonCode, a.DishonoredReturnReasonCode)
+
+	// contested dishonored returns
+	contested, err := ReadFile(filepath.Join("test", "testdata", "contested_addenda.txt"))
+	require.NoError(t, err)
+	out, err = Redact(contested, nil)
+	require.NoError(t, err)
+
+	c, redacted := contested.Batches[0].GetEntries()[0].Addenda99Contested, out.Batches[0].GetEntries()[0].Addenda99Contested
+	require.NotEqual(t, c.OriginalReceivingDFIIdentification, redacted.OriginalReceivingDFIIdentification)
+	require.NotEqual(t, c.ReturnTraceNumberField()[:8], redacted.ReturnTraceNumberField()[:8])
+}
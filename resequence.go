@@ -0,0 +1,204 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+// maxSequenceNumber is the largest value which fits in the seven digit
+// batch number and trace sequence fields.
+const maxSequenceNumber = 9999999
+
+// ResequenceOpts controls how File.Resequence assigns batch and trace numbers.
+type ResequenceOpts struct {
+	// BatchNumberStart is the first batch number assigned. Defaults to 1.
+	BatchNumberStart int `json:"batchNumberStart"`
+	// TraceSequenceStart is the first trace sequence assigned after the ODFI prefix. Defaults to 1.
+	TraceSequenceStart int `json:"traceSequenceStart"`
+	// RestartTraceSequence starts the trace sequence over at TraceSequenceStart for each batch
+	// instead of continuing it across the whole file.
+	RestartTraceSequence bool `json:"restartTraceSequence"`
+}
+
+// ResequencedTrace maps an entry's batch and trace number before Resequence to the values assigned.
+//
+// Entries are identified by old batch and trace number together because files assembled from
+// several systems often repeat trace numbers.
+type ResequencedTrace struct {
+	OldBatchNumber int    `json:"oldBatchNumber"`
+	OldTraceNumber string `json:"oldTraceNumber"`
+	BatchNumber    int    `json:"batchNumber"`
+	TraceNumber    string `json:"traceNumber"`
+}
+
+// Resequence renumbers every batch in the File and assigns new trace numbers (ODFI prefix + sequence)
+// to every domestic and IAT entry. Addenda trace numbers, entry detail sequence numbers and addenda
+// sequence numbers are updated to match, then the File is re-created.
+//
+// Batch numbers are assigned in the order batches are written: Batches followed by IATBatches.
+// ADV batches are renumbered but have no trace numbers. The returned slice lists each entry in file order.
+func (f *File) Resequence(opts *ResequenceOpts) ([]ResequencedTrace, error) {
+	if opts == nil {
+		opts = &ResequenceOpts{}
+	}
+	batchNumber := opts.BatchNumberStart
+	if batchNumber <= 0 {
+		batchNumber = 1
+	}
+	traceStart := opts.TraceSequenceStart
+	if traceStart <= 0 {
+		traceStart = 1
+	}
+	if batchNumber-1+len(f.Batches)+len(f.IATBatches) > maxSequenceNumber {
+		return nil, ErrFileResequenceOverflow
+	}
+
+	// check every trace sequence fits before any entry is changed
+	var entries, mostEntries int
+	count := func(n int) {
+		entries += n
+		if n > mostEntries {
+			mostEntries = n
+		}
+	}
+	for _, b := range f.Batches {
+		count(len(b.GetEntries()))
+	}
+	for i := range f.IATBatches {
+		count(len(f.IATBatches[i].Entries))
+	}
+	if opts.RestartTraceSequence {
+		entries = mostEntries
+	}
+	if traceStart-1+entries > maxSequenceNumber {
+		return nil, ErrFileResequenceOverflow
+	}
+
+	var mapping []ResequencedTrace
+	seq := traceStart
+	nextTrace := func() int {
+		seq++
+		return seq - 1
+	}
+
+	for _, b := range f.Batches {
+		oldBatchNumber := b.GetHeader().BatchNumber
+		setBatchNumber(b, batchNumber)
+		if opts.RestartTraceSequence {
+			seq = traceStart
+		}
+
+		odfi := b.GetHeader().ODFIIdentification
+		for _, ed := range b.GetEntries() {
+			n := nextTrace()
+			old := ed.TraceNumber
+			ed.SetTraceNumber(odfi, n)
+			resequenceAddenda(ed)
+
+			mapping = append(mapping, ResequencedTrace{
+				OldBatchNumber: oldBatchNumber,
+				OldTraceNumber: old,
+				BatchNumber:    batchNumber,
+				TraceNumber:    ed.TraceNumber,
+			})
+		}
+		batchNumber++
+	}
+
+	for i := range f.IATBatches {
+		b := &f.IATBatches[i]
+		oldBatchNumber := b.Header.BatchNumber
+		b.Header.BatchNumber = batchNumber
+		if b.Control != nil {
+			b.Control.BatchNumber = batchNumber
+		}
+		if opts.RestartTraceSequence {
+			seq = traceStart
+		}
+
+		for _, ed := range b.Entries {
+			n := nextTrace()
+			old := ed.TraceNumber
+			ed.SetTraceNumber(b.Header.ODFIIdentification, n)
+			resequenceIATAddenda(ed)
+
+			mapping = append(mapping, ResequencedTrace{
+				OldBatchNumber: oldBatchNumber,
+				OldTraceNumber: old,
+				BatchNumber:    batchNumber,
+				TraceNumber:    ed.TraceNumber,
+			})
+		}
+		batchNumber++
+	}
+
+	if err := f.Create(); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func setBatchNumber(b Batcher, n int) {
+	b.GetHeader().BatchNumber = n
+	if bc := b.GetControl(); bc != nil {
+		bc.BatchNumber = n
+	}
+	if bc := b.GetADVControl(); bc != nil {
+		bc.BatchNumber = n
+	}
+}
+
+// resequenceAddenda points an entry's Addenda05 records at its current trace number.
+func resequenceAddenda(ed *EntryDetail) {
+	entrySeq := ed.parseNumField(ed.TraceNumberField()[8:])
+	for i, a := range ed.Addenda05 {
+		a.SequenceNumber = i + 1
+		a.EntryDetailSequenceNumber = entrySeq
+	}
+}
+
+// resequenceIATAddenda points an IAT entry's Addenda10-18 records at its current trace number.
+func resequenceIATAddenda(ed *IATEntryDetail) {
+	entrySeq := ed.parseNumField(ed.TraceNumberField()[8:])
+	if ed.Addenda10 != nil {
+		ed.Addenda10.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda11 != nil {
+		ed.Addenda11.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda12 != nil {
+		ed.Addenda12.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda13 != nil {
+		ed.Addenda13.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda14 != nil {
+		ed.Addenda14.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda15 != nil {
+		ed.Addenda15.EntryDetailSequenceNumber = entrySeq
+	}
+	if ed.Addenda16 != nil {
+		ed.Addenda16.EntryDetailSequenceNumber = entrySeq
+	}
+	for i, a := range ed.Addenda17 {
+		a.SequenceNumber = i + 1
+		a.EntryDetailSequenceNumber = entrySeq
+	}
+	for i, a := range ed.Addenda18 {
+		a.SequenceNumber = i + 1
+		a.EntryDetailSequenceNumber = entrySeq
+	}
+}
@@ -0,0 +1,158 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile__Resequence(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+
+	iat, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+	file.IATBatches = append(file.IATBatches, iat.IATBatches...)
+	require.NoError(t, file.Create())
+
+	var old []string
+	for _, b := range file.Batches {
+		for _, ed := range b.GetEntries() {
+			old = append(old, ed.TraceNumber)
+		}
+	}
+	for _, b := range file.IATBatches {
+		for _, ed := range b.Entries {
+			old = append(old, ed.TraceNumber)
+		}
+	}
+
+	mapping, err := file.Resequence(&ResequenceOpts{BatchNumberStart: 10, TraceSequenceStart: 100})
+	require.NoError(t, err)
+	require.Len(t, mapping, len(old))
+	require.NoError(t, file.Validate())
+
+	for i := range mapping {
+		require.Equal(t, old[i], mapping[i].OldTraceNumber)
+	}
+
+	// batches are numbered in write order
+	require.Equal(t, 10, file.Batches[0].GetHeader().BatchNumber)
+	require.Equal(t, 10, file.Batches[0].GetControl().BatchNumber)
+	require.Equal(t, 12, file.Batches[2].GetHeader().BatchNumber)
+	require.Equal(t, 13, file.IATBatches[0].Header.BatchNumber)
+	require.Equal(t, 13, file.IATBatches[0].Control.BatchNumber)
+
+	// trace sequences continue across the file
+	first := file.Batches[0].GetEntries()[0]
+	require.Equal(t, file.Batches[0].GetHeader().ODFIIdentification+"0000100", first.TraceNumber)
+	require.Equal(t, first.TraceNumber, mapping[0].TraceNumber)
+	require.Equal(t, 10, mapping[0].BatchNumber)
+
+	last := mapping[len(mapping)-1]
+	iatBatch := file.IATBatches[len(file.IATBatches)-1]
+	iatEntry := iatBatch.Entries[len(iatBatch.Entries)-1]
+	require.Equal(t, iatEntry.TraceNumber, last.TraceNumber)
+	require.Equal(t, 100+len(mapping)-1, iatEntry.parseNumField(iatEntry.TraceNumberField()[8:]))
+	require.Equal(t, iatEntry.TraceNumberField()[8:], iatEntry.Addenda10.EntryDetailSequenceNumberField())
+	for _, a := range iatEntry.Addenda17 {
+		require.Equal(t, iatEntry.TraceNumberField()[8:], a.EntryDetailSequenceNumberField())
+	}
+}
+
+func TestFile__ResequenceRestart(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+
+	_, err = file.Resequence(&ResequenceOpts{RestartTraceSequence: true})
+	require.NoError(t, err)
+	require.NoError(t, file.Validate())
+
+	for i, b := range file.Batches {
+		require.Equal(t, i+1, b.GetHeader().BatchNumber)
+		require.Equal(t, "0000001", b.GetEntries()[0].TraceNumberField()[8:])
+	}
+}
+
+func TestFile__ResequenceAddenda(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+
+	mapping, err := file.Resequence(nil)
+	require.NoError(t, err)
+	require.NoError(t, file.Validate())
+
+	for _, b := range file.Batches {
+		for _, ed := range b.GetEntries() {
+			require.NotNil(t, ed.Addenda99)
+			require.Equal(t, ed.TraceNumber, ed.Addenda99.TraceNumber)
+		}
+	}
+	require.NotEmpty(t, mapping)
+
+	file, err = readACHFilepath(filepath.Join("test", "testdata", "cor-example.ach"))
+	require.NoError(t, err)
+	_, err = file.Resequence(&ResequenceOpts{TraceSequenceStart: 42})
+	require.NoError(t, err)
+
+	ed := file.Batches[0].GetEntries()[0]
+	require.Equal(t, "0000042", ed.TraceNumberField()[8:])
+	require.Equal(t, ed.TraceNumber, ed.Addenda98.TraceNumber)
+
+	// Addenda05 records follow their entry
+	file = mockFilePPD(t)
+	ed = file.Batches[0].GetEntries()[0]
+	ed.AddendaRecordIndicator = 1
+	ed.AddAddenda05(mockAddenda05())
+	ed.AddAddenda05(mockAddenda05())
+	_, err = file.Resequence(&ResequenceOpts{TraceSequenceStart: 7})
+	require.NoError(t, err)
+	for i, a := range ed.Addenda05 {
+		require.Equal(t, i+1, a.SequenceNumber)
+		require.Equal(t, 7, a.EntryDetailSequenceNumber)
+	}
+}
+
+func TestFile__ResequenceOverflow(t *testing.T) {
+	file := mockFilePPD(t)
+	_, err := file.Resequence(&ResequenceOpts{TraceSequenceStart: maxSequenceNumber + 1})
+	require.ErrorIs(t, err, ErrFileResequenceOverflow)
+
+	_, err = file.Resequence(&ResequenceOpts{BatchNumberStart: maxSequenceNumber + 1})
+	require.ErrorIs(t, err, ErrFileResequenceOverflow)
+
+	// the first batch fits but the second doesn't, so nothing is changed
+	file = mockFilePPD(t)
+	b := NewBatchPPD(mockBatchPPDHeader())
+	b.AddEntry(mockPPDEntryDetail())
+	require.NoError(t, b.Create())
+	file.AddBatch(b)
+	require.NoError(t, file.Create())
+	before := string(writeFileBytes(t, file))
+
+	_, err = file.Resequence(&ResequenceOpts{TraceSequenceStart: maxSequenceNumber})
+	require.ErrorIs(t, err, ErrFileResequenceOverflow)
+	require.Equal(t, before, string(writeFileBytes(t, file)))
+
+	// restarting the sequence for each batch fits
+	_, err = file.Resequence(&ResequenceOpts{TraceSequenceStart: maxSequenceNumber, RestartTraceSequence: true})
+	require.NoError(t, err)
+}
@@ -0,0 +1,246 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultSanctionsMinScore is the lowest name similarity SanctionsList reports as a match.
+const DefaultSanctionsMinScore = 0.9
+
+// SanctionedEntity is a record of a sanctions list.
+type SanctionedEntity struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Program string `json:"program,omitempty"`
+}
+
+// SanctionsList is a Screener which fuzzy matches party names against a list read from an
+// SDN-style CSV file. Names are compared without case, punctuation or word order.
+type SanctionsList struct {
+	// MinScore is the lowest Jaro-Winkler similarity reported as a match.
+	// Defaults to DefaultSanctionsMinScore.
+	MinScore float64
+
+	entities []sanctionedName
+}
+
+type sanctionedName struct {
+	entity SanctionedEntity
+	// name and sorted are the normalized name in its original and alphabetical word order
+	name, sorted string
+}
+
+// ReadSanctionsListFile reads the sanctions CSV file at path.
+func ReadSanctionsListFile(path string) (*SanctionsList, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sanctions list: %w", err)
+	}
+	defer fd.Close()
+
+	return ReadSanctionsList(fd)
+}
+
+// ReadSanctionsList reads CSV records in the layout of OFAC's sdn.csv: an entity number, name,
+// type and program followed by any other columns. A header row is skipped and "-0-" is read as empty.
+func ReadSanctionsList(r io.Reader) (*SanctionsList, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	list := &SanctionsList{}
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading sanctions list: %w", err)
+		}
+		if len(record) < 2 {
+			// OFAC's files end with a lone EOF character
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(record[0])); err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("reading sanctions list: line %d: invalid entity number %q", line, record[0])
+		}
+
+		field := func(i int) string {
+			if i >= len(record) {
+				return ""
+			}
+			v := strings.TrimSpace(record[i])
+			if v == "-0-" {
+				return ""
+			}
+			return v
+		}
+		entity := SanctionedEntity{
+			ID:      field(0),
+			Name:    field(1),
+			Type:    field(2),
+			Program: field(3),
+		}
+		name, sorted := normalizeScreeningName(entity.Name)
+		if name == "" {
+			continue
+		}
+		list.entities = append(list.entities, sanctionedName{entity: entity, name: name, sorted: sorted})
+	}
+	if len(list.entities) == 0 {
+		return nil, errors.New("reading sanctions list: no entities found")
+	}
+	return list, nil
+}
+
+// Len returns how many entities are in the list.
+func (l *SanctionsList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.entities)
+}
+
+// Screen returns the entities whose name is at least MinScore similar to the party's name,
+// most similar first.
+func (l *SanctionsList) Screen(party ScreeningParty) ([]ScreeningMatch, error) {
+	if l == nil {
+		return nil, errors.New("nil SanctionsList")
+	}
+	minScore := l.MinScore
+	if minScore <= 0 {
+		minScore = DefaultSanctionsMinScore
+	}
+
+	name, sorted := normalizeScreeningName(party.Name)
+	if name == "" {
+		return nil, nil
+	}
+	var matches []ScreeningMatch
+	for i := range l.entities {
+		e := &l.entities[i]
+		score := jaroWinkler(name, e.name)
+		if s := jaroWinkler(sorted, e.sorted); s > score {
+			score = s
+		}
+		if score >= minScore {
+			matches = append(matches, ScreeningMatch{
+				Party:      party,
+				EntityID:   e.entity.ID,
+				EntityName: e.entity.Name,
+				Program:    e.entity.Program,
+				Score:      score,
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+// normalizeScreeningName uppercases a name and removes its punctuation, returning its
+// words in their original and alphabetical order.
+func normalizeScreeningName(name string) (string, string) {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	normalized := strings.Join(words, " ")
+	sort.Strings(words)
+	return normalized, strings.Join(words, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings between 0 and 1
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	longest := len(s1)
+	if len(s2) > longest {
+		longest = len(s2)
+	}
+	window := longest/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	var matches int
+	for i := range s1 {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(s2) {
+			hi = len(s2)
+		}
+		for j := lo; j < hi; j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	var transpositions, k int
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	var prefix int
+	for prefix < 4 && prefix < len(s1) && prefix < len(s2) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
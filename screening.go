@@ -0,0 +1,209 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Roles of the parties in a ScreeningParty
+const (
+	// ScreeningOriginator is the originator of an IAT entry from Addenda11 and Addenda12
+	ScreeningOriginator = "Originator"
+	// ScreeningReceiver is the receiver of an IAT entry from Addenda10, Addenda15 and Addenda16
+	ScreeningReceiver = "Receiver"
+	// ScreeningODFI is the originating bank of an IAT entry from Addenda13
+	ScreeningODFI = "ODFI"
+	// ScreeningRDFI is the receiving bank of an IAT entry from Addenda14
+	ScreeningRDFI = "RDFI"
+	// ScreeningCorrespondent is a foreign correspondent bank of an IAT entry from Addenda18
+	ScreeningCorrespondent = "Correspondent"
+	// ScreeningIndividual is the IndividualName of a domestic entry
+	ScreeningIndividual = "Individual"
+)
+
+// ScreeningParty is a name and address from an entry which is checked by a Screener.
+type ScreeningParty struct {
+	Role              string `json:"role"`
+	Name              string `json:"name"`
+	StreetAddress     string `json:"streetAddress,omitempty"`
+	CityStateProvince string `json:"cityStateProvince,omitempty"`
+	CountryPostalCode string `json:"countryPostalCode,omitempty"`
+	// CountryCode is the branch country of ODFI, RDFI and Correspondent parties
+	CountryCode string `json:"countryCode,omitempty"`
+
+	BatchNumber int    `json:"batchNumber"`
+	TraceNumber string `json:"traceNumber"`
+}
+
+// ScreeningMatch is a sanctioned entity which a ScreeningParty matches.
+type ScreeningMatch struct {
+	Party      ScreeningParty `json:"party"`
+	EntityID   string         `json:"entityID"`
+	EntityName string         `json:"entityName"`
+	Program    string         `json:"program,omitempty"`
+	// Score is how closely the names match, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// Screener checks a party against a sanctions list. SanctionsList is a Screener which
+// reads a list from disk, or one backed by a screening service can be used.
+type Screener interface {
+	Screen(party ScreeningParty) ([]ScreeningMatch, error)
+}
+
+// ScreeningOpts are the options for ScreenFile.
+type ScreeningOpts struct {
+	// IncludeDomestic screens the IndividualName of entries in non-IAT batches
+	IncludeDomestic bool `json:"includeDomestic"`
+	// Secondary sets SecondaryOFACScreeningIndicator rather than OFACScreeningIndicator
+	// for screening done after the gateway operator.
+	Secondary bool `json:"secondary"`
+}
+
+// ScreeningReport is the result of ScreenFile.
+type ScreeningReport struct {
+	// Screened is how many parties were screened
+	Screened int              `json:"screened"`
+	Matches  []ScreeningMatch `json:"matches"`
+}
+
+// ScreenFile checks every IAT party in the File with screener. Each IATEntryDetail's
+// OFACScreeningIndicator, or SecondaryOFACScreeningIndicator, is set to 1 when any of its
+// parties match and 0 otherwise.
+func ScreenFile(f *File, screener Screener, opts *ScreeningOpts) (*ScreeningReport, error) {
+	if f == nil {
+		return nil, errors.New("screening: nil File")
+	}
+	if screener == nil {
+		return nil, errors.New("screening: nil Screener")
+	}
+	if opts == nil {
+		opts = &ScreeningOpts{}
+	}
+
+	report := &ScreeningReport{}
+	screen := func(party ScreeningParty) (bool, error) {
+		if strings.TrimSpace(party.Name) == "" {
+			return false, nil
+		}
+		report.Screened++
+		matches, err := screener.Screen(party)
+		if err != nil {
+			return false, fmt.Errorf("screening %s %s: %w", party.Role, party.TraceNumber, err)
+		}
+		report.Matches = append(report.Matches, matches...)
+		return len(matches) > 0, nil
+	}
+
+	for i := range f.IATBatches {
+		bh := f.IATBatches[i].GetHeader()
+		for _, ed := range f.IATBatches[i].Entries {
+			var hit bool
+			for _, party := range iatParties(bh.BatchNumber, ed) {
+				matched, err := screen(party)
+				if err != nil {
+					return report, err
+				}
+				hit = hit || matched
+			}
+			indicator := "0"
+			if hit {
+				indicator = "1"
+			}
+			if opts.Secondary {
+				ed.SecondaryOFACScreeningIndicator = indicator
+			} else {
+				ed.OFACScreeningIndicator = indicator
+			}
+		}
+	}
+
+	if opts.IncludeDomestic {
+		for _, b := range f.Batches {
+			bh := b.GetHeader()
+			for _, ed := range b.GetEntries() {
+				_, err := screen(ScreeningParty{
+					Role:        ScreeningIndividual,
+					Name:        ed.IndividualName,
+					BatchNumber: bh.BatchNumber,
+					TraceNumber: ed.TraceNumber,
+				})
+				if err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// iatParties returns the originator, receiver and banks of an IATEntryDetail
+func iatParties(batchNumber int, ed *IATEntryDetail) []ScreeningParty {
+	party := func(role string) ScreeningParty {
+		return ScreeningParty{Role: role, BatchNumber: batchNumber, TraceNumber: ed.TraceNumber}
+	}
+
+	var parties []ScreeningParty
+	if ed.Addenda11 != nil {
+		p := party(ScreeningOriginator)
+		p.Name = ed.Addenda11.OriginatorName
+		p.StreetAddress = ed.Addenda11.OriginatorStreetAddress
+		if ed.Addenda12 != nil {
+			p.CityStateProvince = ed.Addenda12.OriginatorCityStateProvince
+			p.CountryPostalCode = ed.Addenda12.OriginatorCountryPostalCode
+		}
+		parties = append(parties, p)
+	}
+	if ed.Addenda10 != nil {
+		p := party(ScreeningReceiver)
+		p.Name = ed.Addenda10.Name
+		if ed.Addenda15 != nil {
+			p.StreetAddress = ed.Addenda15.ReceiverStreetAddress
+		}
+		if ed.Addenda16 != nil {
+			p.CityStateProvince = ed.Addenda16.ReceiverCityStateProvince
+			p.CountryPostalCode = ed.Addenda16.ReceiverCountryPostalCode
+		}
+		parties = append(parties, p)
+	}
+	if ed.Addenda13 != nil {
+		p := party(ScreeningODFI)
+		p.Name = ed.Addenda13.ODFIName
+		p.CountryCode = ed.Addenda13.ODFIBranchCountryCode
+		parties = append(parties, p)
+	}
+	if ed.Addenda14 != nil {
+		p := party(ScreeningRDFI)
+		p.Name = ed.Addenda14.RDFIName
+		p.CountryCode = ed.Addenda14.RDFIBranchCountryCode
+		parties = append(parties, p)
+	}
+	for _, a := range ed.Addenda18 {
+		if a == nil {
+			continue
+		}
+		p := party(ScreeningCorrespondent)
+		p.Name = a.ForeignCorrespondentBankName
+		p.CountryCode = a.ForeignCorrespondentBankBranchCountryCode
+		parties = append(parties, p)
+	}
+	return parties
+}
@@ -0,0 +1,129 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockScreener struct {
+	parties []ScreeningParty
+	err     error
+}
+
+func (s *mockScreener) Screen(party ScreeningParty) ([]ScreeningMatch, error) {
+	s.parties = append(s.parties, party)
+	return nil, s.err
+}
+
+func TestScreenFile(t *testing.T) {
+	list, err := ReadSanctionsListFile(filepath.Join("test", "testdata", "sdn.csv"))
+	require.NoError(t, err)
+
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "iat-debit.ach"))
+	require.NoError(t, err)
+	ed := file.IATBatches[0].Entries[0]
+
+	// the originator, BEK Solutions, matches
+	report, err := ScreenFile(file, list, nil)
+	require.NoError(t, err)
+	require.Equal(t, 5, report.Screened)
+	require.Len(t, report.Matches, 1)
+
+	match := report.Matches[0]
+	require.Equal(t, ScreeningOriginator, match.Party.Role)
+	require.Equal(t, "BEK Solutions", match.Party.Name)
+	require.Equal(t, "15 West Place Street", match.Party.StreetAddress)
+	require.Equal(t, ed.TraceNumber, match.Party.TraceNumber)
+	require.Equal(t, "1001", match.EntityID)
+	require.Equal(t, "SDGT", match.Program)
+	require.Equal(t, "1", ed.OFACScreeningIndicator)
+
+	// indicators are kept when the file is written and read back
+	read, err := NewReader(bytes.NewReader(writeFileBytes(t, file))).Read()
+	require.NoError(t, err)
+	require.Equal(t, "1", read.IATBatches[0].Entries[0].OFACScreeningIndicator)
+
+	ed.Addenda11.OriginatorName = "Other Solutions"
+	_, err = ScreenFile(file, list, &ScreeningOpts{Secondary: true})
+	require.NoError(t, err)
+	require.Equal(t, "0", ed.SecondaryOFACScreeningIndicator)
+}
+
+func TestScreenFile__Domestic(t *testing.T) {
+	file := mockFilePPD(t)
+	ed := file.Batches[0].GetEntries()[0]
+
+	screener := &mockScreener{}
+	report, err := ScreenFile(file, screener, nil)
+	require.NoError(t, err)
+	require.Zero(t, report.Screened)
+
+	_, err = ScreenFile(file, screener, &ScreeningOpts{IncludeDomestic: true})
+	require.NoError(t, err)
+	require.Len(t, screener.parties, 1)
+	require.Equal(t, ScreeningIndividual, screener.parties[0].Role)
+	require.Equal(t, ed.IndividualName, screener.parties[0].Name)
+
+	screener.err = errors.New("unavailable")
+	_, err = ScreenFile(file, screener, &ScreeningOpts{IncludeDomestic: true})
+	require.ErrorContains(t, err, "unavailable")
+
+	_, err = ScreenFile(file, nil, nil)
+	require.Error(t, err)
+}
+
+func TestSanctionsList(t *testing.T) {
+	list, err := ReadSanctionsList(strings.NewReader(`1002,"NORTHWIND TRADING COMPANY LIMITED",-0- ,IRAN
+1003,"DOE, Jonathan Alexander","individual",SDNTK
+` + "\x1a\n"))
+	require.NoError(t, err)
+	require.Equal(t, 2, list.Len())
+
+	screen := func(name string) []ScreeningMatch {
+		matches, err := list.Screen(ScreeningParty{Name: name})
+		require.NoError(t, err)
+		return matches
+	}
+	require.Len(t, screen("Jonathan Alexander Doe"), 1)
+	require.Len(t, screen("Northwind Trading Co. Limited"), 1)
+	require.Empty(t, screen("Jane Doe"))
+	require.Empty(t, screen(" "))
+
+	list.MinScore = 0.6
+	require.Len(t, screen("Jane Doe"), 1)
+
+	_, err = ReadSanctionsList(strings.NewReader("1,NAME\nabc,OTHER\n"))
+	require.ErrorContains(t, err, "line 2")
+	_, err = ReadSanctionsList(strings.NewReader(""))
+	require.Error(t, err)
+}
+
+func TestJaroWinkler(t *testing.T) {
+	require.Equal(t, 1.0, jaroWinkler("MARTHA", "MARTHA"))
+	require.InDelta(t, 0.961, jaroWinkler("MARTHA", "MARHTA"), 0.001)
+	require.InDelta(t, 0.840, jaroWinkler("DWAYNE", "DUANE"), 0.001)
+	require.Zero(t, jaroWinkler("ABC", ""))
+	require.Zero(t, jaroWinkler("ABC", "XYZ"))
+}
@@ -19,12 +19,93 @@ package ach
 
 // SegmentFileConfiguration contains configuration setting for sorting during Segment File Creation.
 //
-// It is currently not defined, but can/will be expanded later and File.SegmentFile enhanced to use the
-// configuration settings
-type SegmentFileConfiguration struct{}
+// Files are always segmented into credits and debits. Each option splits the segments further and
+// requires File.SegmentFileBy as File.SegmentFile only returns one credit and one debit File.
+type SegmentFileConfiguration struct {
+	// BySECCode places entries of each StandardEntryClassCode into separate files.
+	BySECCode bool `json:"bySECCode"`
+
+	// ByServiceClassCode places entries from batches of each ServiceClassCode into separate files.
+	ByServiceClassCode bool `json:"byServiceClassCode"`
+
+	// ByCompanyIdentification places entries of each CompanyIdentification into separate files.
+	// IAT entries are segmented by their OriginatorIdentification.
+	ByCompanyIdentification bool `json:"byCompanyIdentification"`
+
+	// ByRDFI places entries for each RDFIIdentification into separate files.
+	ByRDFI bool `json:"byRDFI"`
+
+	// SeparatePrenotes places prenotification entries into separate files.
+	SeparatePrenotes bool `json:"separatePrenotes"`
+
+	// SeparateZeroDollar places zero dollar remittance entries into separate files.
+	SeparateZeroDollar bool `json:"separateZeroDollar"`
+}
 
 // SegmentFileConfiguration returns a new SegmentFileConfiguration with default values for non exported fields
 func NewSegmentFileConfiguration() *SegmentFileConfiguration {
 	sfc := &SegmentFileConfiguration{}
 	return sfc
 }
+
+// segmentsByCreditsAndDebits returns true when only credits and debits are segmented.
+func (sfc *SegmentFileConfiguration) segmentsByCreditsAndDebits() bool {
+	if sfc == nil {
+		return true
+	}
+	return *sfc == SegmentFileConfiguration{}
+}
+
+// SegmentKey identifies the entries placed into a segmented File.
+// Fields other than CreditOrDebit are only set when SegmentFileConfiguration segments by them.
+type SegmentKey struct {
+	// CreditOrDebit is "C" for credits and "D" for debits
+	CreditOrDebit string `json:"creditOrDebit"`
+
+	StandardEntryClassCode string `json:"standardEntryClassCode,omitempty"`
+	ServiceClassCode       int    `json:"serviceClassCode,omitempty"`
+	CompanyIdentification  string `json:"companyIdentification,omitempty"`
+	RDFIIdentification     string `json:"RDFIIdentification,omitempty"`
+	Prenote                bool   `json:"prenote,omitempty"`
+	ZeroDollar             bool   `json:"zeroDollar,omitempty"`
+}
+
+// segmentKey returns the SegmentKey for an entry according to the configuration.
+func (sfc *SegmentFileConfiguration) segmentKey(creditOrDebit string, secCode string, serviceClassCode int, companyIdentification string, rdfi string, transactionCode int) SegmentKey {
+	key := SegmentKey{
+		CreditOrDebit: creditOrDebit,
+	}
+	if sfc == nil {
+		return key
+	}
+	if sfc.BySECCode {
+		key.StandardEntryClassCode = secCode
+	}
+	if sfc.ByServiceClassCode {
+		key.ServiceClassCode = serviceClassCode
+	}
+	if sfc.ByCompanyIdentification {
+		key.CompanyIdentification = companyIdentification
+	}
+	if sfc.ByRDFI {
+		key.RDFIIdentification = rdfi
+	}
+	if sfc.SeparatePrenotes {
+		key.Prenote = (&validator{}).isPrenote(transactionCode)
+	}
+	if sfc.SeparateZeroDollar {
+		key.ZeroDollar = isZeroDollarRemittance(transactionCode)
+	}
+	return key
+}
+
+func isZeroDollarRemittance(code int) bool {
+	switch code {
+	case CheckingZeroDollarRemittanceCredit, CheckingZeroDollarRemittanceDebit,
+		SavingsZeroDollarRemittanceCredit, SavingsZeroDollarRemittanceDebit,
+		GLZeroDollarRemittanceCredit, GLZeroDollarRemittanceDebit,
+		LoanZeroDollarRemittanceCredit:
+		return true
+	}
+	return false
+}
@@ -32,3 +32,28 @@ func TestSegmentFileConfiguration(t *testing.T) {
 		t.Error("mockSegmentFileConfiguration does not validate and will break other tests")
 	}
 }
+
+func TestSegmentFileConfiguration__segmentKey(t *testing.T) {
+	var sfc *SegmentFileConfiguration
+	if !sfc.segmentsByCreditsAndDebits() {
+		t.Error("nil SegmentFileConfiguration should only segment credits and debits")
+	}
+	key := sfc.segmentKey("C", PPD, MixedDebitsAndCredits, "123456789", "23138010", CheckingPrenoteCredit)
+	if key != (SegmentKey{CreditOrDebit: "C"}) {
+		t.Errorf("unexpected key: %#v", key)
+	}
+
+	sfc = &SegmentFileConfiguration{
+		BySECCode:          true,
+		SeparatePrenotes:   true,
+		SeparateZeroDollar: true,
+	}
+	if sfc.segmentsByCreditsAndDebits() {
+		t.Error("expected further segmenting")
+	}
+	key = sfc.segmentKey("D", PPD, DebitsOnly, "123456789", "23138010", CheckingZeroDollarRemittanceDebit)
+	expected := SegmentKey{CreditOrDebit: "D", StandardEntryClassCode: PPD, ZeroDollar: true}
+	if key != expected {
+		t.Errorf("unexpected key: %#v", key)
+	}
+}
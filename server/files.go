@@ -88,7 +88,16 @@ func createFileEndpoint(s Service, r Repository, logger log.Logger) endpoint.End
 			req.File.SetValidation(req.validateOpts)
 		}
 
-		err := r.StoreFile(req.File)
+		// Files over the service's exposure limits are rejected before they're stored
+		var err error
+		if checker, ok := s.(exposureLimitChecker); ok && req.parseError == nil {
+			if req.validateOpts == nil || !req.validateOpts.SkipAll {
+				err = checker.CheckExposureLimits(req.File)
+			}
+		}
+		if err == nil {
+			err = r.StoreFile(req.File)
+		}
 		if logger != nil {
 			logger := logger.With(log.Fields{
 				"files":     log.String("createFile"),
@@ -893,3 +902,56 @@ func decodeFlattenBatchesRequest(_ context.Context, r *http.Request) (interface{
 		requestID: moovhttp.GetRequestID(r),
 	}, nil
 }
+
+type summarizeFileRequest struct {
+	ID        string
+	requestID string
+}
+
+type summarizeFileResponse struct {
+	Summary *ach.Summary `json:"summary"`
+	Err     error        `json:"error"`
+}
+
+func (r summarizeFileResponse) error() error { return r.Err }
+
+func summarizeFileEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(summarizeFileRequest)
+		if !ok {
+			return summarizeFileResponse{Err: ErrFoundABug}, ErrFoundABug
+		}
+
+		f, err := s.GetFile(req.ID)
+
+		if logger != nil {
+			logger := logger.With(log.Fields{
+				"files":     log.String("summarizeFile"),
+				"requestID": log.String(req.requestID),
+			})
+			if err != nil {
+				logger.Error().LogError(err)
+			} else {
+				logger.Info().Log("summarize file")
+			}
+		}
+		if err != nil {
+			return summarizeFileResponse{Err: err}, nil
+		}
+		return summarizeFileResponse{
+			Summary: ach.Summarize(f),
+		}, nil
+	}
+}
+
+func decodeSummarizeFileRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return summarizeFileRequest{
+		ID:        id,
+		requestID: moovhttp.GetRequestID(r),
+	}, nil
+}
@@ -1362,3 +1362,130 @@ func TestFiles__flattenFileEndpointError(t *testing.T) {
 		t.Errorf("resp.Err=%q", resp.Err)
 	}
 }
+
+func TestFiles__summarizeFileEndpoint(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+	svc := NewService(repo)
+	router := MakeHTTPHandler(svc, repo, kitlog.NewNopLogger())
+
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+	defer fd.Close()
+	f, err := ach.NewReader(fd).Read()
+	require.NoError(t, err)
+	file := &f
+	file.ID = "summary"
+	require.NoError(t, repo.StoreFile(file))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/files/summary/summary", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Summary *ach.Summary `json:"summary"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Equal(t, 1, resp.Summary.Files)
+	require.Equal(t, file.Control.TotalDebitEntryDollarAmountInFile, resp.Summary.BySECCode[ach.PPD].DebitAmount)
+
+	// unknown files are not found
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/files/other/summary", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFiles__ExposureLimits(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+	limits := &ach.ExposureLimits{
+		SECCodes: map[string]ach.ExposureLimit{ach.PPD: {DailyDebitLimit: 1}},
+	}
+	svc := NewServiceWithLimits(repo, limits)
+	router := MakeHTTPHandler(svc, repo, kitlog.NewNopLogger())
+
+	bs, err := os.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/files/create", bytes.NewReader(bs))
+	router.ServeHTTP(w, req)
+	w.Flush()
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp struct {
+		ID    string `json:"id"`
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Contains(t, resp.Error, "exposure limit exceeded: PPD debits")
+
+	// the file isn't stored
+	_, err = repo.FindFile(resp.ID)
+	require.Error(t, err)
+
+	// skipAll ignores limits
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/files/create?skipAll=true", bytes.NewReader(bs))
+	router.ServeHTTP(w, req)
+	w.Flush()
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	// the stored file fails validation
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", fmt.Sprintf("/files/%s/validate", resp.ID), nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "exposure limit exceeded")
+
+	limits.SECCodes[ach.PPD] = ach.ExposureLimit{DailyDebitLimit: 100000000}
+	require.NoError(t, svc.ValidateFile(resp.ID, nil))
+}
+
+func TestFiles__ExposureLimitsAcrossFiles(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+	limits := &ach.ExposureLimits{
+		Originators: map[string]ach.OriginatorLimits{
+			"121042882": {ExposureLimit: ach.ExposureLimit{DailyDebitLimit: 150000000}},
+		},
+	}
+	svc := NewServiceWithLimits(repo, limits)
+	router := MakeHTTPHandler(svc, repo, kitlog.NewNopLogger())
+
+	bs, err := os.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	require.NoError(t, err)
+
+	create := func(body []byte) (*httptest.ResponseRecorder, string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/files/create", bytes.NewReader(body))
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		var resp struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(bytes.NewReader(w.Body.Bytes())).Decode(&resp))
+		return w, resp.ID
+	}
+
+	// the first file is under the daily limit
+	w, firstID := create(bs)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// the same entries again take the originator over its daily limit
+	w, secondID := create(bs)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "exposure limit exceeded: originator 121042882 debits")
+	_, err = repo.FindFile(secondID)
+	require.Error(t, err)
+
+	// entries effective on another day are counted separately
+	w, _ = create(bytes.Replace(bs, []byte("190625"), []byte("190626"), 1))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, svc.ValidateFile(firstID, nil))
+}
@@ -28,6 +28,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
 	moovhttp "github.com/moov-io/base/http"
 	"github.com/moov-io/base/log"
@@ -145,6 +146,12 @@ func MakeHTTPHandler(s Service, repo Repository, kitlog gokitlog.Logger) http.Ha
 		encodeTextResponse,
 		options...,
 	))
+	r.Methods("GET").Path("/files/{id}/summary").Handler(httptransport.NewServer(
+		summarizeFileEndpoint(s, logger),
+		decodeSummarizeFileRequest,
+		encodeResponse,
+		options...,
+	))
 	r.Methods("GET").Path("/files/{id}/validate").Handler(httptransport.NewServer(
 		validateFileEndpoint(s, logger),
 		decodeValidateFileRequest,
@@ -313,6 +320,10 @@ func codeFrom(err error) int {
 		return http.StatusOK
 	}
 
+	if errors.Is(err, ach.ErrExposureLimitExceeded) {
+		return http.StatusBadRequest
+	}
+
 	errString := fmt.Sprintf("%#v", err)
 	if el, ok := err.(base.ErrorList); ok {
 		errString = el.Error()
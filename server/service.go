@@ -69,7 +69,8 @@ type Service interface {
 
 // service a concrete implementation of the service.
 type service struct {
-	store Repository
+	store  Repository
+	limits *ach.ExposureLimits
 }
 
 // NewService creates a new concrete service
@@ -79,6 +80,15 @@ func NewService(r Repository) Service {
 	}
 }
 
+// NewServiceWithLimits creates a new concrete service which checks files as they're created
+// and validated against limits.
+func NewServiceWithLimits(r Repository, limits *ach.ExposureLimits) Service {
+	return &service{
+		store:  r,
+		limits: limits,
+	}
+}
+
 // CreateFile add a file to storage
 // TODO(adam): the HTTP endpoint accepts malformed bodies (and missing data)
 func (s *service) CreateFile(fh *ach.FileHeader) (string, error) {
@@ -159,7 +169,46 @@ func (s *service) ValidateFile(id string, opts *ach.ValidateOpts) error {
 	if err != nil {
 		return fmt.Errorf("problem reading file %s: %v", id, err)
 	}
-	return f.ValidateWith(opts)
+	if err := f.ValidateWith(opts); err != nil {
+		return err
+	}
+	if s.limits != nil && (opts == nil || (opts.ExposureLimits == nil && !opts.SkipAll)) {
+		return s.CheckExposureLimits(f)
+	}
+	return nil
+}
+
+// exposureLimitChecker is implemented by services created with NewServiceWithLimits
+type exposureLimitChecker interface {
+	// CheckExposureLimits returns an *ach.ExposureLimitError when the file, counted along with every
+	// other stored file, exceeds the service's ExposureLimits
+	CheckExposureLimits(f *ach.File) error
+}
+
+func (s *service) CheckExposureLimits(f *ach.File) error {
+	// Limits are daily, so entries in stored files for the same originator and EffectiveEntryDate
+	// count towards the totals. The file is counted last so breaches list its entries.
+	var files []*ach.File
+	for _, stored := range s.store.FindAllFiles() {
+		if stored != nil && stored.ID != f.ID {
+			files = append(files, stored)
+		}
+	}
+	files = append(files, f)
+
+	var breaches []ach.LimitBreach
+	for _, b := range ach.CheckExposureLimits(s.limits, files...) {
+		for _, entry := range b.Entries {
+			if entry.FileID == f.ID {
+				breaches = append(breaches, b)
+				break
+			}
+		}
+	}
+	if len(breaches) > 0 {
+		return &ach.ExposureLimitError{Breaches: breaches}
+	}
+	return nil
 }
 
 func (s *service) CreateBatch(fileID string, batch ach.Batcher) (string, error) {
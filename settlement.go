@@ -0,0 +1,246 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/moov-io/base"
+)
+
+// SettlementWindow is a Same Day ACH processing window. Files created at or before the Cutoff
+// ("15:04") with entries effective that day settle in the window.
+type SettlementWindow struct {
+	Name   string `json:"name"`
+	Cutoff string `json:"cutoff"`
+}
+
+// DefaultSettlementWindows are the Federal Reserve's Same Day ACH submission deadlines (Eastern Time).
+var DefaultSettlementWindows = []SettlementWindow{
+	{Name: "SDA1", Cutoff: "10:30"},
+	{Name: "SDA2", Cutoff: "14:45"},
+	{Name: "SDA3", Cutoff: "16:45"},
+}
+
+// SettlementOpts are the options for CalculateSettlement.
+type SettlementOpts struct {
+	// Windows are the Same Day ACH windows, in the time zone of FileCreationTime.
+	// Defaults to DefaultSettlementWindows.
+	Windows []SettlementWindow `json:"windows"`
+}
+
+// SettlementPosition is the gross and net amounts which settle for an ODFI and destination on a day.
+type SettlementPosition struct {
+	ODFIIdentification   string `json:"ODFIIdentification"`
+	ImmediateDestination string `json:"immediateDestination"`
+	// SettlementDate is formatted as YYYY-MM-DD
+	SettlementDate string `json:"settlementDate"`
+	// Window is the name of the Same Day window the entries settle in, empty for entries
+	// which settle on a future day.
+	Window string `json:"window,omitempty"`
+
+	Credits      int `json:"credits"`
+	GrossCredits int `json:"grossCredits"`
+	Debits       int `json:"debits"`
+	GrossDebits  int `json:"grossDebits"`
+
+	// ADVCredits and ADVDebits are the ADVEntryDetail amounts credited and debited to the institution
+	ADVCredits int `json:"advCredits"`
+	ADVDebits  int `json:"advDebits"`
+
+	// NetPosition is GrossDebits - GrossCredits + ADVCredits - ADVDebits. A positive NetPosition
+	// is owed to the ODFI and a negative NetPosition is owed by the ODFI.
+	NetPosition int `json:"netPosition"`
+}
+
+// SettlementReport holds the settlement positions of files sorted by settlement date, window, ODFI and destination.
+type SettlementReport struct {
+	Positions []*SettlementPosition `json:"positions"`
+}
+
+type settlementKey struct {
+	odfi, destination, date, window string
+}
+
+// CalculateSettlement computes the gross credits, gross debits and net position which settle for
+// each ODFI, destination and settlement date across files.
+//
+// Entries effective after their file's creation date settle on their EffectiveEntryDate, or the next
+// banking day if that's a weekend or holiday. Entries effective on or before the creation date are
+// Same Day entries and settle in the first window whose cutoff the file was created by. Files created
+// after the last window settle on the next banking day.
+func CalculateSettlement(files []*File, opts *SettlementOpts) (*SettlementReport, error) {
+	windows := DefaultSettlementWindows
+	if opts != nil && len(opts.Windows) > 0 {
+		windows = opts.Windows
+	}
+	// cutoffs are compared as zero padded "15:04" strings
+	windows = append([]SettlementWindow(nil), windows...)
+	for i := range windows {
+		cutoff, err := time.Parse("15:04", windows[i].Cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("settlement window %s: invalid cutoff %q", windows[i].Name, windows[i].Cutoff)
+		}
+		windows[i].Cutoff = cutoff.Format("15:04")
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Cutoff < windows[j].Cutoff
+	})
+
+	positions := make(map[settlementKey]*SettlementPosition)
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		created, err := time.Parse("0601021504", file.Header.FileCreationDate+file.Header.FileCreationTime)
+		if err != nil {
+			created, err = time.Parse("060102", file.Header.FileCreationDate)
+			if err != nil {
+				return nil, fmt.Errorf("settlement: invalid FileCreationDate %q", file.Header.FileCreationDate)
+			}
+		}
+
+		position := func(odfi, effective string) *SettlementPosition {
+			date, window := settlementDate(created, effective, windows)
+			key := settlementKey{odfi: odfi, destination: file.Header.ImmediateDestination, date: date, window: window}
+			p, exists := positions[key]
+			if !exists {
+				p = &SettlementPosition{
+					ODFIIdentification:   odfi,
+					ImmediateDestination: file.Header.ImmediateDestination,
+					SettlementDate:       date,
+					Window:               window,
+				}
+				positions[key] = p
+			}
+			return p
+		}
+
+		for _, b := range file.Batches {
+			bh := b.GetHeader()
+			p := position(bh.ODFIIdentification, bh.EffectiveEntryDate)
+			for _, ed := range b.GetEntries() {
+				p.addEntry(ed.TransactionCode, ed.Amount)
+			}
+			for _, ed := range b.GetADVEntries() {
+				switch advCreditOrDebit(ed.TransactionCode) {
+				case "C":
+					p.ADVCredits += ed.Amount
+				case "D":
+					p.ADVDebits += ed.Amount
+				}
+			}
+		}
+		for i := range file.IATBatches {
+			bh := file.IATBatches[i].GetHeader()
+			p := position(bh.ODFIIdentification, bh.EffectiveEntryDate)
+			for _, ed := range file.IATBatches[i].Entries {
+				p.addEntry(ed.TransactionCode, ed.Amount)
+			}
+		}
+	}
+
+	report := &SettlementReport{
+		Positions: make([]*SettlementPosition, 0, len(positions)),
+	}
+	for _, p := range positions {
+		p.NetPosition = p.GrossDebits - p.GrossCredits + p.ADVCredits - p.ADVDebits
+		report.Positions = append(report.Positions, p)
+	}
+	sort.Slice(report.Positions, func(i, j int) bool {
+		a, b := report.Positions[i], report.Positions[j]
+		switch {
+		case a.SettlementDate != b.SettlementDate:
+			return a.SettlementDate < b.SettlementDate
+		case a.Window != b.Window:
+			return a.Window < b.Window
+		case a.ODFIIdentification != b.ODFIIdentification:
+			return a.ODFIIdentification < b.ODFIIdentification
+		default:
+			return a.ImmediateDestination < b.ImmediateDestination
+		}
+	})
+	return report, nil
+}
+
+func (p *SettlementPosition) addEntry(transactionCode, amount int) {
+	switch creditOrDebit(transactionCode) {
+	case "C":
+		p.Credits++
+		p.GrossCredits += amount
+	case "D":
+		p.Debits++
+		p.GrossDebits += amount
+	}
+}
+
+// settlementDate returns the day (YYYY-MM-DD) and Same Day window entries with the EffectiveEntryDate settle in.
+func settlementDate(created time.Time, effectiveEntryDate string, windows []SettlementWindow) (string, string) {
+	createdDay := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+
+	effective, err := time.Parse("060102", effectiveEntryDate)
+	if err == nil && effective.After(createdDay) {
+		day := base.NewTime(effective)
+		if !day.IsBankingDay() {
+			day = day.AddBankingDay(1)
+		}
+		return day.Time.Format("2006-01-02"), ""
+	}
+
+	day := base.NewTime(createdDay)
+	if day.IsBankingDay() {
+		clock := created.Format("15:04")
+		for _, w := range windows {
+			if clock <= w.Cutoff {
+				return day.Time.Format("2006-01-02"), w.Name
+			}
+		}
+	}
+	return day.AddBankingDay(1).Time.Format("2006-01-02"), ""
+}
+
+// WriteCSV writes the report's positions as CSV with a header row.
+func (r *SettlementReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{
+		"settlementDate", "window", "ODFIIdentification", "immediateDestination",
+		"credits", "grossCredits", "debits", "grossDebits", "advCredits", "advDebits", "netPosition",
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range r.Positions {
+		err := cw.Write([]string{
+			p.SettlementDate, p.Window, p.ODFIIdentification, p.ImmediateDestination,
+			strconv.Itoa(p.Credits), strconv.Itoa(p.GrossCredits),
+			strconv.Itoa(p.Debits), strconv.Itoa(p.GrossDebits),
+			strconv.Itoa(p.ADVCredits), strconv.Itoa(p.ADVDebits),
+			strconv.Itoa(p.NetPosition),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,172 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockSettlementFile(t *testing.T, created string, effectiveDates ...string) *File {
+	t.Helper()
+
+	file := mockFilePPD(t)
+	file.Header.FileCreationDate, file.Header.FileCreationTime = created[:6], created[6:]
+	file.Batches = nil
+	for _, date := range effectiveDates {
+		bh := mockBatchPPDHeader()
+		bh.ServiceClassCode = MixedDebitsAndCredits
+		bh.EffectiveEntryDate = date
+		b := NewBatchPPD(bh)
+
+		credit := mockPPDEntryDetail()
+		credit.Amount = 10000
+		b.AddEntry(credit)
+
+		debit := mockPPDEntryDetail()
+		debit.TransactionCode = CheckingDebit
+		debit.Amount = 2500
+		debit.TraceNumber = "121042880000002"
+		b.AddEntry(debit)
+
+		require.NoError(t, b.Create())
+		file.AddBatch(b)
+	}
+	return file
+}
+
+func TestCalculateSettlement(t *testing.T) {
+	// Monday October 19th 2026
+	morning := mockSettlementFile(t, "2610190900", "261019", "261020")
+	evening := mockSettlementFile(t, "2610191700", "261019", "261024")
+
+	report, err := CalculateSettlement([]*File{morning, nil, evening}, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Positions, 3)
+
+	// same day entries in the morning settle in the first window
+	sda1 := report.Positions[0]
+	require.Equal(t, "2026-10-19", sda1.SettlementDate)
+	require.Equal(t, "SDA1", sda1.Window)
+	require.Equal(t, "12104288", sda1.ODFIIdentification)
+	require.Equal(t, "231380104", sda1.ImmediateDestination)
+	require.Equal(t, 1, sda1.Credits)
+	require.Equal(t, 10000, sda1.GrossCredits)
+	require.Equal(t, 1, sda1.Debits)
+	require.Equal(t, 2500, sda1.GrossDebits)
+	require.Equal(t, -7500, sda1.NetPosition)
+
+	// future dated entries and same day entries after the last window settle the next day
+	nextDay := report.Positions[1]
+	require.Equal(t, "2026-10-20", nextDay.SettlementDate)
+	require.Empty(t, nextDay.Window)
+	require.Equal(t, 2, nextDay.Credits)
+	require.Equal(t, 20000, nextDay.GrossCredits)
+	require.Equal(t, -15000, nextDay.NetPosition)
+
+	// Saturday effective dates settle on Monday
+	require.Equal(t, "2026-10-26", report.Positions[2].SettlementDate)
+
+	// custom windows
+	report, err = CalculateSettlement([]*File{evening}, &SettlementOpts{
+		Windows: []SettlementWindow{{Name: "LATE", Cutoff: "18:00"}, {Name: "EARLY", Cutoff: "8:00"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "2026-10-19", report.Positions[0].SettlementDate)
+	require.Equal(t, "LATE", report.Positions[0].Window)
+
+	_, err = CalculateSettlement([]*File{evening}, &SettlementOpts{Windows: []SettlementWindow{{Name: "bad", Cutoff: "noon"}}})
+	require.Error(t, err)
+
+	evening.Header.FileCreationDate = "abc"
+	_, err = CalculateSettlement([]*File{evening}, nil)
+	require.Error(t, err)
+}
+
+func TestCalculateSettlement__ADV(t *testing.T) {
+	codes := map[int]bool{
+		CreditForDebitsOriginated:     true,
+		CreditForCreditsReceived:      true,
+		CreditForCreditsRejected:      true,
+		CreditSummary:                 true,
+		DebitForCreditsOriginated:     false,
+		DebitForDebitsReceived:        false,
+		DebitForDebitsRejectedBatches: false,
+		DebitSummary:                  false,
+	}
+	for code, credit := range codes {
+		file := mockFileADV(t)
+		file.Header.FileCreationDate, file.Header.FileCreationTime = "261019", "1000"
+		file.Batches[0].GetHeader().EffectiveEntryDate = "261019"
+		ed := file.Batches[0].GetADVEntries()[0]
+		ed.TransactionCode = code
+		ed.Amount = 50000
+
+		report, err := CalculateSettlement([]*File{file}, nil)
+		require.NoError(t, err)
+		require.Len(t, report.Positions, 1)
+
+		p := report.Positions[0]
+		require.Zero(t, p.Credits+p.Debits)
+		if credit {
+			require.Equal(t, 50000, p.ADVCredits, "code %d", code)
+			require.Zero(t, p.ADVDebits, "code %d", code)
+			require.Equal(t, 50000, p.NetPosition, "code %d", code)
+		} else {
+			require.Equal(t, 50000, p.ADVDebits, "code %d", code)
+			require.Zero(t, p.ADVCredits, "code %d", code)
+			require.Equal(t, -50000, p.NetPosition, "code %d", code)
+		}
+	}
+}
+
+func TestSettlementReport__Export(t *testing.T) {
+	report, err := CalculateSettlement([]*File{mockSettlementFile(t, "2610190900", "261019", "261020")}, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, "netPosition", records[0][10])
+	require.Equal(t, []string{"2026-10-19", "SDA1", "12104288", "231380104", "1", "10000", "1", "2500", "0", "0", "-7500"}, records[1])
+
+	bs, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), `"netPosition":-7500`)
+
+	// write errors are returned, including those from rows larger than the csv.Writer's buffer
+	for len(report.Positions) < 200 {
+		report.Positions = append(report.Positions, report.Positions[0])
+	}
+	require.ErrorIs(t, report.WriteCSV(failingWriter{}), errFailingWriter)
+}
+
+var errFailingWriter = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errFailingWriter
+}
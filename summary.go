@@ -0,0 +1,215 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"sort"
+)
+
+// Summary holds entry counts and dollar totals across one or more files, grouped a few different ways.
+type Summary struct {
+	Files   int `json:"files"`
+	Batches int `json:"batches"`
+
+	// Totals covers every entry in the files
+	Totals SummaryTotals `json:"totals"`
+
+	BySECCode               map[string]*SummaryTotals `json:"bySECCode"`
+	ByServiceClassCode      map[int]*SummaryTotals    `json:"byServiceClassCode"`
+	ByTransactionCode       map[int]*SummaryTotals    `json:"byTransactionCode"`
+	ByRDFIIdentification    map[string]*SummaryTotals `json:"byRDFIIdentification"`
+	ByCompanyIdentification map[string]*SummaryTotals `json:"byCompanyIdentification"`
+	ByEffectiveEntryDate    map[string]*SummaryTotals `json:"byEffectiveEntryDate"`
+
+	// Groups holds totals for each combination of SEC code, ServiceClassCode, TransactionCode,
+	// RDFI, company and effective date found in the files. They answer questions like
+	// "how much PPD debit is going to an RDFI today?"
+	Groups []*SummaryGroup `json:"groups"`
+
+	// ReturnCodes counts the Addenda99 records by ReturnCode, Addenda99Dishonored records by
+	// DishonoredReturnReasonCode and Addenda99Contested records by ContestedReturnCode
+	ReturnCodes map[string]int `json:"returnCodes"`
+	// ChangeCodes counts the Addenda98 records by ChangeCode and Addenda98Refused records by RefusedChangeCode
+	ChangeCodes map[string]int `json:"changeCodes"`
+}
+
+// SummaryTotals are the entry counts and amounts for a group of entries.
+// Entries which are neither debits nor credits are only included in Entries.
+type SummaryTotals struct {
+	Entries      int `json:"entries"`
+	Debits       int `json:"debits"`
+	DebitAmount  int `json:"debitAmount"`
+	Credits      int `json:"credits"`
+	CreditAmount int `json:"creditAmount"`
+}
+
+// add counts an entry, where creditOrDebit is "C", "D" or empty
+func (t *SummaryTotals) add(creditOrDebit string, amount int) {
+	t.Entries++
+	switch creditOrDebit {
+	case "C":
+		t.Credits++
+		t.CreditAmount += amount
+	case "D":
+		t.Debits++
+		t.DebitAmount += amount
+	}
+}
+
+// SummaryGroup are the totals of entries which share every grouped field.
+type SummaryGroup struct {
+	StandardEntryClassCode string `json:"standardEntryClassCode"`
+	ServiceClassCode       int    `json:"serviceClassCode"`
+	TransactionCode        int    `json:"transactionCode"`
+	RDFIIdentification     string `json:"RDFIIdentification"`
+	CompanyIdentification  string `json:"companyIdentification"`
+	EffectiveEntryDate     string `json:"effectiveEntryDate"`
+
+	SummaryTotals
+}
+
+// Summarize totals the entries of files. Nil files are skipped.
+//
+// IAT entries are grouped under their batch's OriginatorIdentification as the company.
+// ADV entries are included with the ADV batch's header fields, where odd transaction codes are
+// credits and even codes are debits.
+func Summarize(files ...*File) *Summary {
+	s := &Summary{
+		BySECCode:               make(map[string]*SummaryTotals),
+		ByServiceClassCode:      make(map[int]*SummaryTotals),
+		ByTransactionCode:       make(map[int]*SummaryTotals),
+		ByRDFIIdentification:    make(map[string]*SummaryTotals),
+		ByCompanyIdentification: make(map[string]*SummaryTotals),
+		ByEffectiveEntryDate:    make(map[string]*SummaryTotals),
+		ReturnCodes:             make(map[string]int),
+		ChangeCodes:             make(map[string]int),
+	}
+	groups := make(map[SummaryGroup]*SummaryGroup)
+
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		s.Files++
+
+		for _, b := range file.Batches {
+			s.Batches++
+			bh := b.GetHeader()
+			key := SummaryGroup{
+				StandardEntryClassCode: bh.StandardEntryClassCode,
+				ServiceClassCode:       bh.ServiceClassCode,
+				CompanyIdentification:  bh.CompanyIdentification,
+				EffectiveEntryDate:     bh.EffectiveEntryDate,
+			}
+			for _, ed := range b.GetEntries() {
+				key.TransactionCode, key.RDFIIdentification = ed.TransactionCode, ed.RDFIIdentification
+				s.add(groups, key, creditOrDebit(ed.TransactionCode), ed.Amount)
+				if ed.Addenda98 != nil {
+					s.ChangeCodes[ed.Addenda98.ChangeCode]++
+				}
+				if ed.Addenda98Refused != nil {
+					s.ChangeCodes[ed.Addenda98Refused.RefusedChangeCode]++
+				}
+				if ed.Addenda99 != nil {
+					s.ReturnCodes[ed.Addenda99.ReturnCode]++
+				}
+				if ed.Addenda99Dishonored != nil {
+					s.ReturnCodes[ed.Addenda99Dishonored.DishonoredReturnReasonCode]++
+				}
+				if ed.Addenda99Contested != nil {
+					s.ReturnCodes[ed.Addenda99Contested.ContestedReturnCode]++
+				}
+			}
+			for _, ed := range b.GetADVEntries() {
+				key.TransactionCode, key.RDFIIdentification = ed.TransactionCode, ed.RDFIIdentification
+				s.add(groups, key, advCreditOrDebit(ed.TransactionCode), ed.Amount)
+			}
+		}
+
+		for i := range file.IATBatches {
+			s.Batches++
+			bh := file.IATBatches[i].GetHeader()
+			key := SummaryGroup{
+				StandardEntryClassCode: bh.StandardEntryClassCode,
+				ServiceClassCode:       bh.ServiceClassCode,
+				CompanyIdentification:  bh.OriginatorIdentification,
+				EffectiveEntryDate:     bh.EffectiveEntryDate,
+			}
+			for _, ed := range file.IATBatches[i].Entries {
+				key.TransactionCode, key.RDFIIdentification = ed.TransactionCode, ed.RDFIIdentification
+				s.add(groups, key, creditOrDebit(ed.TransactionCode), ed.Amount)
+				if ed.Addenda98 != nil {
+					s.ChangeCodes[ed.Addenda98.ChangeCode]++
+				}
+				if ed.Addenda99 != nil {
+					s.ReturnCodes[ed.Addenda99.ReturnCode]++
+				}
+			}
+		}
+	}
+
+	s.Groups = make([]*SummaryGroup, 0, len(groups))
+	for _, g := range groups {
+		s.Groups = append(s.Groups, g)
+	}
+	sort.Slice(s.Groups, func(i, j int) bool {
+		a, b := s.Groups[i], s.Groups[j]
+		switch {
+		case a.EffectiveEntryDate != b.EffectiveEntryDate:
+			return a.EffectiveEntryDate < b.EffectiveEntryDate
+		case a.StandardEntryClassCode != b.StandardEntryClassCode:
+			return a.StandardEntryClassCode < b.StandardEntryClassCode
+		case a.ServiceClassCode != b.ServiceClassCode:
+			return a.ServiceClassCode < b.ServiceClassCode
+		case a.TransactionCode != b.TransactionCode:
+			return a.TransactionCode < b.TransactionCode
+		case a.RDFIIdentification != b.RDFIIdentification:
+			return a.RDFIIdentification < b.RDFIIdentification
+		default:
+			return a.CompanyIdentification < b.CompanyIdentification
+		}
+	})
+	return s
+}
+
+func (s *Summary) add(groups map[SummaryGroup]*SummaryGroup, key SummaryGroup, creditOrDebit string, amount int) {
+	s.Totals.add(creditOrDebit, amount)
+	totalsFor(s.BySECCode, key.StandardEntryClassCode).add(creditOrDebit, amount)
+	totalsFor(s.ByServiceClassCode, key.ServiceClassCode).add(creditOrDebit, amount)
+	totalsFor(s.ByTransactionCode, key.TransactionCode).add(creditOrDebit, amount)
+	totalsFor(s.ByRDFIIdentification, key.RDFIIdentification).add(creditOrDebit, amount)
+	totalsFor(s.ByCompanyIdentification, key.CompanyIdentification).add(creditOrDebit, amount)
+	totalsFor(s.ByEffectiveEntryDate, key.EffectiveEntryDate).add(creditOrDebit, amount)
+
+	g, exists := groups[key]
+	if !exists {
+		g = &SummaryGroup{}
+		*g = key
+		groups[key] = g
+	}
+	g.add(creditOrDebit, amount)
+}
+
+func totalsFor[K comparable](m map[K]*SummaryTotals, key K) *SummaryTotals {
+	t, exists := m[key]
+	if !exists {
+		t = &SummaryTotals{}
+		m[key] = t
+	}
+	return t
+}
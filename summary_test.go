@@ -0,0 +1,154 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	web, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	require.NoError(t, err)
+	mixed, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	require.NoError(t, err)
+
+	s := Summarize(web, nil, mixed)
+	require.Equal(t, 2, s.Files)
+	require.Equal(t, len(web.Batches)+len(mixed.Batches), s.Batches)
+
+	// totals match the file controls
+	require.Equal(t, web.Control.TotalDebitEntryDollarAmountInFile+mixed.Control.TotalDebitEntryDollarAmountInFile, s.Totals.DebitAmount)
+	require.Equal(t, web.Control.TotalCreditEntryDollarAmountInFile+mixed.Control.TotalCreditEntryDollarAmountInFile, s.Totals.CreditAmount)
+	require.Equal(t, s.Totals.Entries, s.Totals.Debits+s.Totals.Credits)
+
+	require.Len(t, s.BySECCode, 2)
+	var webCredits int
+	for _, b := range web.Batches {
+		if b.GetHeader().StandardEntryClassCode == WEB {
+			webCredits += b.GetControl().TotalCreditEntryDollarAmount
+		}
+	}
+	require.Equal(t, webCredits, s.BySECCode[WEB].CreditAmount)
+	require.Equal(t, s.Totals.DebitAmount, s.BySECCode[WEB].DebitAmount+s.BySECCode[PPD].DebitAmount)
+
+	// every grouping adds up to the totals
+	sum := func(totals []*SummaryTotals) (out SummaryTotals) {
+		for _, t := range totals {
+			out.Entries += t.Entries
+			out.Debits += t.Debits
+			out.DebitAmount += t.DebitAmount
+			out.Credits += t.Credits
+			out.CreditAmount += t.CreditAmount
+		}
+		return
+	}
+	var rdfis, groups []*SummaryTotals
+	for _, t := range s.ByRDFIIdentification {
+		rdfis = append(rdfis, t)
+	}
+	for _, g := range s.Groups {
+		groups = append(groups, &g.SummaryTotals)
+	}
+	require.Equal(t, s.Totals, sum(rdfis))
+	require.Equal(t, s.Totals, sum(groups))
+
+	for i := 1; i < len(s.Groups); i++ {
+		require.LessOrEqual(t, s.Groups[i-1].EffectiveEntryDate, s.Groups[i].EffectiveEntryDate)
+	}
+
+	bs, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), `"byTransactionCode":{"22"`)
+}
+
+func TestSummarize__ReturnsAndNOCs(t *testing.T) {
+	returns, err := readACHFilepath(filepath.Join("test", "testdata", "return-WEB.ach"))
+	require.NoError(t, err)
+	cor, err := readACHFilepath(filepath.Join("test", "testdata", "cor-example.ach"))
+	require.NoError(t, err)
+	iat, err := readACHFilepath(filepath.Join("test", "testdata", "20180716-IAT-A17-A18.ach"))
+	require.NoError(t, err)
+
+	s := Summarize(returns, cor, iat)
+	require.Equal(t, 3, s.Files)
+
+	var returned int
+	for _, b := range returns.Batches {
+		returned += len(b.GetEntries())
+	}
+	var total int
+	for _, n := range s.ReturnCodes {
+		total += n
+	}
+	require.Equal(t, returned, total)
+	require.Equal(t, 1, s.ChangeCodes[cor.Batches[0].GetEntries()[0].Addenda98.ChangeCode])
+
+	require.Contains(t, s.BySECCode, IAT)
+	require.Contains(t, s.ByCompanyIdentification, iat.IATBatches[0].Header.OriginatorIdentification)
+}
+
+func TestSummarize__DishonoredReturns(t *testing.T) {
+	dishonored, err := readACHFilepath(filepath.Join("test", "testdata", "return-dishonored.ach"))
+	require.NoError(t, err)
+	contested, err := ReadFile(filepath.Join("test", "testdata", "contested_addenda.txt"))
+	require.NoError(t, err)
+
+	s := Summarize(dishonored, contested)
+	require.Equal(t, map[string]int{"R69": 2, "R72": 1}, s.ReturnCodes)
+
+	// refused NOCs are counted by their refused change code
+	cor, err := readACHFilepath(filepath.Join("test", "testdata", "cor-example.ach"))
+	require.NoError(t, err)
+	ed := cor.Batches[0].GetEntries()[0]
+	ed.Addenda98, ed.Addenda98Refused = nil, NewAddenda98Refused()
+	ed.Addenda98Refused.RefusedChangeCode = "C61"
+	s = Summarize(cor)
+	require.Equal(t, 1, s.ChangeCodes["C61"])
+}
+
+func TestSummarize__ADV(t *testing.T) {
+	credits := map[int]bool{
+		CreditForDebitsOriginated:     true,
+		CreditForCreditsReceived:      true,
+		CreditForCreditsRejected:      true,
+		CreditSummary:                 true,
+		DebitForCreditsOriginated:     false,
+		DebitForDebitsReceived:        false,
+		DebitForDebitsRejectedBatches: false,
+		DebitSummary:                  false,
+	}
+	for code, credit := range credits {
+		file := mockFileADV(t)
+		ed := file.Batches[0].GetADVEntries()[0]
+		ed.TransactionCode = code
+		ed.Amount = 50000
+
+		s := Summarize(file)
+		for _, totals := range []SummaryTotals{s.Totals, *s.BySECCode[ADV], *s.ByTransactionCode[code], s.Groups[0].SummaryTotals} {
+			if credit {
+				require.Equal(t, SummaryTotals{Entries: 1, Credits: 1, CreditAmount: 50000}, totals, "code %d", code)
+			} else {
+				require.Equal(t, SummaryTotals{Entries: 1, Debits: 1, DebitAmount: 50000}, totals, "code %d", code)
+			}
+		}
+	}
+}
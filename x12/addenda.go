@@ -0,0 +1,109 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moov-io/ach"
+)
+
+// paymentInformationLength is the length of the PaymentRelatedInformation field of an Addenda05
+const paymentInformationLength = 80
+
+// maxAddenda is the most Addenda05 records a CTX entry can have
+const maxAddenda = 9999
+
+// Reassemble joins the PaymentRelatedInformation of addenda in SequenceNumber order.
+func Reassemble(addenda []*ach.Addenda05) string {
+	sorted := make([]*ach.Addenda05, 0, len(addenda))
+	for _, a := range addenda {
+		if a != nil {
+			sorted = append(sorted, a)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SequenceNumber < sorted[j].SequenceNumber
+	})
+
+	var buf strings.Builder
+	for _, a := range sorted {
+		buf.WriteString(a.PaymentRelatedInformation)
+	}
+	return buf.String()
+}
+
+// FromAddenda parses the interchange carried in addenda.
+func FromAddenda(addenda []*ach.Addenda05) (*Interchange, error) {
+	return Parse(Reassemble(addenda))
+}
+
+// Split divides data into Addenda05 records numbered from 1.
+//
+// Addenda05 records are read with leading and trailing spaces trimmed, so records are only
+// split between two non-space characters and can be shorter than 80 characters.
+func Split(data string) []*ach.Addenda05 {
+	var out []*ach.Addenda05
+	for data != "" {
+		n := splitIndex(data)
+		a := ach.NewAddenda05()
+		a.PaymentRelatedInformation = data[:n]
+		a.SequenceNumber = len(out) + 1
+		out = append(out, a)
+		data = data[n:]
+	}
+	return out
+}
+
+func splitIndex(data string) int {
+	if len(data) <= paymentInformationLength {
+		return len(data)
+	}
+	for i := paymentInformationLength; i > 1; i-- {
+		if data[i-1] != ' ' && data[i] != ' ' {
+			return i
+		}
+	}
+	return paymentInformationLength
+}
+
+// ToAddenda writes the interchange and splits it into Addenda05 records.
+func ToAddenda(ic *Interchange) []*ach.Addenda05 {
+	return Split(ic.String())
+}
+
+// SetRemittance replaces the Addenda05 records of a CTX entry with the interchange and updates
+// its AddendaRecordIndicator and addenda record count.
+func SetRemittance(ed *ach.EntryDetail, ic *Interchange) error {
+	addenda := ToAddenda(ic)
+	if len(addenda) > maxAddenda {
+		return fmt.Errorf("x12: %d addenda records exceeds the CTX limit of %d", len(addenda), maxAddenda)
+	}
+
+	seq, _ := strconv.Atoi(strings.TrimSpace(ed.TraceNumberField()[8:]))
+	for _, a := range addenda {
+		a.EntryDetailSequenceNumber = seq
+	}
+	ed.Addenda05 = addenda
+	ed.AddendaRecordIndicator = 1
+	ed.SetCATXAddendaRecords(len(addenda))
+	return nil
+}
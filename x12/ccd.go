@@ -0,0 +1,199 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/moov-io/ach"
+)
+
+// BankingConventionDelimiters are the delimiters of the single X12 segment carried in a CCD+ or
+// PPD+ Addenda05, such as RMR*IV*12345**100.00\
+var BankingConventionDelimiters = Delimiters{
+	Element:   '*',
+	Component: '>',
+	Segment:   '\\',
+}
+
+var (
+	// ErrSegmentTerminator is the error given when an Addenda05 segment doesn't end with a terminator
+	ErrSegmentTerminator = errors.New("x12: segment must end with a \\ or ~ terminator")
+	// ErrSegmentSyntax is the error given when an Addenda05 doesn't contain a single valid segment
+	ErrSegmentSyntax = errors.New("x12: invalid segment")
+)
+
+// ParseSegment reads the single segment of a CCD+ or PPD+ Addenda05's PaymentRelatedInformation.
+// The segment must end with a \ terminator, or the ~ used by some originators.
+func ParseSegment(info string) (Segment, error) {
+	info = strings.TrimSpace(info)
+	if info == "" {
+		return Segment{}, ErrSegmentTerminator
+	}
+	d := BankingConventionDelimiters
+	switch info[len(info)-1] {
+	case '\\':
+	case '~':
+		d.Segment = '~'
+	default:
+		return Segment{}, ErrSegmentTerminator
+	}
+	body := info[:len(info)-1]
+	if strings.IndexByte(body, d.Segment) >= 0 {
+		return Segment{}, fmt.Errorf("%w: more than one segment", ErrSegmentSyntax)
+	}
+
+	parts := strings.Split(body, string(d.Element))
+	seg := Segment{ID: parts[0], Elements: parts[1:]}
+	if err := validSegmentID(seg.ID); err != nil {
+		return Segment{}, err
+	}
+	if len(seg.Elements) == 0 {
+		return Segment{}, fmt.Errorf("%w: %s has no elements", ErrSegmentSyntax, seg.ID)
+	}
+	return seg, nil
+}
+
+func validSegmentID(id string) error {
+	if n := len(id); n < 2 || n > 3 {
+		return fmt.Errorf("%w: segment ID %q", ErrSegmentSyntax, id)
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("%w: segment ID %q", ErrSegmentSyntax, id)
+		}
+	}
+	return nil
+}
+
+// SegmentAddenda writes a single segment into an Addenda05 with the banking convention delimiters.
+// An error is returned when an element contains a delimiter or the segment doesn't fit in the record.
+func SegmentAddenda(seg Segment) (*ach.Addenda05, error) {
+	if err := validSegmentID(seg.ID); err != nil {
+		return nil, err
+	}
+	d := BankingConventionDelimiters
+	for i, e := range seg.Elements {
+		if strings.ContainsAny(e, string([]byte{d.Element, d.Segment, '~'})) {
+			return nil, fmt.Errorf("%w: %s%02d contains a delimiter", ErrSegmentSyntax, seg.ID, i+1)
+		}
+	}
+	info := seg.String(d)
+	if len(info) > paymentInformationLength {
+		return nil, fmt.Errorf("%w: %s is %d characters, over the %d character limit", ErrSegmentSyntax, seg.ID, len(info), paymentInformationLength)
+	}
+
+	a := ach.NewAddenda05()
+	a.PaymentRelatedInformation = info
+	return a, nil
+}
+
+// Segment returns the RMR segment.
+func (r RMR) Segment() Segment {
+	return Segment{ID: "RMR", Elements: []string{r.Qualifier, r.ReferenceID, r.PaymentActionCode, r.Amount, r.InvoiceAmount, r.DiscountAmount}}
+}
+
+// Segment returns the REF segment.
+func (r REF) Segment() Segment {
+	return Segment{ID: "REF", Elements: []string{r.Qualifier, r.ID, r.Description}}
+}
+
+// Segment returns the TRN segment.
+func (t TRN) Segment() Segment {
+	return Segment{ID: "TRN", Elements: []string{t.TraceTypeCode, t.ReferenceID, t.OriginatingCompanyID, t.SecondaryReferenceID}}
+}
+
+// RMRAddenda returns an Addenda05 with the RMR segment, e.g. RMR*IV*12345**100.00\
+func RMRAddenda(r RMR) (*ach.Addenda05, error) {
+	if r.Qualifier == "" || r.ReferenceID == "" {
+		return nil, fmt.Errorf("%w: RMR01 and RMR02 are required", ErrSegmentSyntax)
+	}
+	return SegmentAddenda(r.Segment())
+}
+
+// REFAddenda returns an Addenda05 with the REF segment, e.g. REF*PO*4500012345\
+func REFAddenda(r REF) (*ach.Addenda05, error) {
+	if r.Qualifier == "" || (r.ID == "" && r.Description == "") {
+		return nil, fmt.Errorf("%w: REF01 and REF02 or REF03 are required", ErrSegmentSyntax)
+	}
+	return SegmentAddenda(r.Segment())
+}
+
+// TRNAddenda returns an Addenda05 with the TRN segment, e.g. TRN*1*12345*1512345678\
+func TRNAddenda(t TRN) (*ach.Addenda05, error) {
+	if t.TraceTypeCode == "" || t.ReferenceID == "" {
+		return nil, fmt.Errorf("%w: TRN01 and TRN02 are required", ErrSegmentSyntax)
+	}
+	return SegmentAddenda(t.Segment())
+}
+
+// ParseRMR reads the RMR segment of an Addenda05.
+func ParseRMR(a *ach.Addenda05) (*RMR, error) {
+	seg, err := parseAddendaSegment(a, "RMR")
+	if err != nil {
+		return nil, err
+	}
+	return &RMR{
+		Qualifier:         seg.Element(1),
+		ReferenceID:       seg.Element(2),
+		PaymentActionCode: seg.Element(3),
+		Amount:            seg.Element(4),
+		InvoiceAmount:     seg.Element(5),
+		DiscountAmount:    seg.Element(6),
+	}, nil
+}
+
+// ParseREF reads the REF segment of an Addenda05.
+func ParseREF(a *ach.Addenda05) (*REF, error) {
+	seg, err := parseAddendaSegment(a, "REF")
+	if err != nil {
+		return nil, err
+	}
+	return &REF{Qualifier: seg.Element(1), ID: seg.Element(2), Description: seg.Element(3)}, nil
+}
+
+// ParseTRN reads the TRN segment of an Addenda05.
+func ParseTRN(a *ach.Addenda05) (*TRN, error) {
+	seg, err := parseAddendaSegment(a, "TRN")
+	if err != nil {
+		return nil, err
+	}
+	return &TRN{
+		TraceTypeCode:        seg.Element(1),
+		ReferenceID:          seg.Element(2),
+		OriginatingCompanyID: seg.Element(3),
+		SecondaryReferenceID: seg.Element(4),
+	}, nil
+}
+
+func parseAddendaSegment(a *ach.Addenda05, id string) (Segment, error) {
+	if a == nil {
+		return Segment{}, errors.New("x12: nil Addenda05")
+	}
+	seg, err := ParseSegment(a.PaymentRelatedInformation)
+	if err != nil {
+		return Segment{}, err
+	}
+	if seg.ID != id {
+		return Segment{}, fmt.Errorf("%w: expected %s segment but found %s", ErrSegmentSyntax, id, seg.ID)
+	}
+	return seg, nil
+}
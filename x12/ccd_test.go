@@ -0,0 +1,97 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRMRAddenda(t *testing.T) {
+	a, err := RMRAddenda(RMR{Qualifier: "IV", ReferenceID: "12345", Amount: "100.00"})
+	require.NoError(t, err)
+	require.Equal(t, `RMR*IV*12345**100.00\`, a.PaymentRelatedInformation)
+	require.Equal(t, "05", a.TypeCode)
+
+	rmr, err := ParseRMR(a)
+	require.NoError(t, err)
+	require.Equal(t, "12345", rmr.ReferenceID)
+	require.Equal(t, "100.00", rmr.Amount)
+	require.Empty(t, rmr.PaymentActionCode)
+
+	_, err = RMRAddenda(RMR{Qualifier: "IV"})
+	require.ErrorIs(t, err, ErrSegmentSyntax)
+	_, err = RMRAddenda(RMR{Qualifier: "IV", ReferenceID: `12*45`})
+	require.ErrorContains(t, err, "RMR02 contains a delimiter")
+	_, err = RMRAddenda(RMR{Qualifier: "IV", ReferenceID: strings.Repeat("1", 80)})
+	require.ErrorContains(t, err, "character limit")
+}
+
+func TestREFAndTRNAddenda(t *testing.T) {
+	a, err := REFAddenda(REF{Qualifier: "PO", ID: "4500012345"})
+	require.NoError(t, err)
+	require.Equal(t, `REF*PO*4500012345\`, a.PaymentRelatedInformation)
+	ref, err := ParseREF(a)
+	require.NoError(t, err)
+	require.Equal(t, REF{Qualifier: "PO", ID: "4500012345"}, *ref)
+
+	a, err = TRNAddenda(TRN{TraceTypeCode: "1", ReferenceID: "12345", OriginatingCompanyID: "1512345678"})
+	require.NoError(t, err)
+	require.Equal(t, `TRN*1*12345*1512345678\`, a.PaymentRelatedInformation)
+	trn, err := ParseTRN(a)
+	require.NoError(t, err)
+	require.Equal(t, "1512345678", trn.OriginatingCompanyID)
+
+	_, err = ParseREF(a)
+	require.ErrorContains(t, err, "expected REF segment but found TRN")
+
+	_, err = REFAddenda(REF{Qualifier: "PO"})
+	require.Error(t, err)
+	_, err = TRNAddenda(TRN{ReferenceID: "1"})
+	require.Error(t, err)
+}
+
+func TestParseSegment(t *testing.T) {
+	seg, err := ParseSegment(` RMR*IV*12345**100.00~ `)
+	require.NoError(t, err)
+	require.Equal(t, "RMR", seg.ID)
+	require.Equal(t, "100.00", seg.Element(4))
+
+	for _, info := range []string{"", `RMR*IV*12345`, `RMR*IV\REF*PO\`, `rmr*IV\`, `RMRX*IV\`, `RMR\`} {
+		_, err := ParseSegment(info)
+		require.Error(t, err, info)
+	}
+
+	// the Addenda05 is valid Nacha data when written
+	a, err := RMRAddenda(RMR{Qualifier: "IV", ReferenceID: "12345", Amount: "100.00"})
+	require.NoError(t, err)
+	a.SequenceNumber = 1
+	a.EntryDetailSequenceNumber = 1
+	require.NoError(t, a.Validate())
+
+	read := ach.NewAddenda05()
+	read.Parse(a.String())
+	_, err = ParseRMR(read)
+	require.NoError(t, err)
+
+	_, err = ParseRMR(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,181 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moov-io/ach"
+)
+
+// ChildSupportEntryDescription is the CompanyEntryDescription of child support batches
+const ChildSupportEntryDescription = "CHILD SUPP"
+
+// DED is the Child Support banking convention carried in the Addenda05 of a CCD+ entry, e.g.
+//
+//	DED*CS*CASE123456*260415*25000*123456789*N*SMITH JO*06037*N\
+type DED struct {
+	// CaseID is the case identifier assigned by the state
+	CaseID string `json:"caseID"`
+	// PayDate is the YYMMDD date income was withheld
+	PayDate string `json:"payDate"`
+	// Amount is the amount withheld in cents
+	Amount int `json:"amount"`
+	// SSN is the non-custodial parent's Social Security Number
+	SSN string `json:"ssn"`
+	// MedicalSupport is true when family medical support is available
+	MedicalSupport bool `json:"medicalSupport"`
+	// Name is the non-custodial parent's name, up to 10 characters
+	Name string `json:"name"`
+	// FIPSCode is the 5 or 7 character Federal Information Processing Standards code of the receiving state agency
+	FIPSCode string `json:"fipsCode,omitempty"`
+	// EmploymentTerminated is true when the non-custodial parent's employment has ended
+	EmploymentTerminated bool `json:"employmentTerminated"`
+}
+
+// Validate checks the DED fields are present and well formed.
+func (d DED) Validate() error {
+	if d.CaseID == "" || len(d.CaseID) > 20 {
+		return fmt.Errorf("%w: DED02 case identifier %q", ErrSegmentSyntax, d.CaseID)
+	}
+	if _, err := time.Parse("060102", d.PayDate); err != nil {
+		return fmt.Errorf("%w: DED03 pay date %q", ErrSegmentSyntax, d.PayDate)
+	}
+	if d.Amount <= 0 || d.Amount > 9999999999 {
+		return fmt.Errorf("%w: DED04 amount %d", ErrSegmentSyntax, d.Amount)
+	}
+	if len(d.SSN) != 9 || strings.Trim(d.SSN, "0123456789") != "" {
+		return fmt.Errorf("%w: DED05 SSN must be 9 digits", ErrSegmentSyntax)
+	}
+	if d.Name == "" || len(d.Name) > 10 {
+		return fmt.Errorf("%w: DED07 name %q", ErrSegmentSyntax, d.Name)
+	}
+	if n := len(d.FIPSCode); n != 0 && n != 5 && n != 7 {
+		return fmt.Errorf("%w: DED08 FIPS code %q", ErrSegmentSyntax, d.FIPSCode)
+	}
+	return nil
+}
+
+// Segment returns the DED segment.
+func (d DED) Segment() Segment {
+	return Segment{ID: "DED", Elements: []string{
+		"CS", d.CaseID, d.PayDate, strconv.Itoa(d.Amount), d.SSN, yesNo(d.MedicalSupport), d.Name, d.FIPSCode, yesNo(d.EmploymentTerminated),
+	}}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
+
+// DEDAddenda validates the DED and returns an Addenda05 with its segment.
+func DEDAddenda(d DED) (*ach.Addenda05, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return SegmentAddenda(d.Segment())
+}
+
+// ParseDED reads and validates the DED segment of an Addenda05.
+func ParseDED(a *ach.Addenda05) (*DED, error) {
+	seg, err := parseAddendaSegment(a, "DED")
+	if err != nil {
+		return nil, err
+	}
+	if app := seg.Element(1); app != "CS" {
+		return nil, fmt.Errorf("%w: DED01 application identifier %q", ErrSegmentSyntax, app)
+	}
+	amount, err := strconv.Atoi(seg.Element(4))
+	if err != nil {
+		return nil, fmt.Errorf("%w: DED04 amount %q", ErrSegmentSyntax, seg.Element(4))
+	}
+	d := &DED{
+		CaseID:               seg.Element(2),
+		PayDate:              seg.Element(3),
+		Amount:               amount,
+		SSN:                  seg.Element(5),
+		MedicalSupport:       seg.Element(6) == "Y",
+		Name:                 seg.Element(7),
+		FIPSCode:             seg.Element(8),
+		EmploymentTerminated: seg.Element(9) == "Y",
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ChildSupportPayment is a child support withholding sent to a state disbursement unit.
+type ChildSupportPayment struct {
+	DED
+	// RDFIIdentification is the 9 digit routing number of the state disbursement unit's bank
+	RDFIIdentification string
+	DFIAccountNumber   string
+	// TransactionCode defaults to ach.CheckingCredit
+	TransactionCode int
+	// ReceivingCompany is the name of the state disbursement unit
+	ReceivingCompany string
+}
+
+// ChildSupportBatch builds a CCD batch of child support payments with a DED Addenda05 on each entry.
+// The batch header's StandardEntryClassCode, ServiceClassCode and CompanyEntryDescription are set
+// for child support and trace numbers are assigned from its ODFIIdentification.
+func ChildSupportBatch(bh *ach.BatchHeader, payments []ChildSupportPayment) (*ach.BatchCCD, error) {
+	if bh == nil {
+		return nil, errors.New("x12: nil BatchHeader")
+	}
+	if len(payments) == 0 {
+		return nil, errors.New("x12: no child support payments")
+	}
+
+	header := *bh
+	header.StandardEntryClassCode = ach.CCD
+	header.ServiceClassCode = ach.CreditsOnly
+	header.CompanyEntryDescription = ChildSupportEntryDescription
+	batch := ach.NewBatchCCD(&header)
+
+	for i, p := range payments {
+		addenda05, err := DEDAddenda(p.DED)
+		if err != nil {
+			return nil, fmt.Errorf("child support payment %d: %w", i+1, err)
+		}
+		ed := ach.NewEntryDetail()
+		ed.TransactionCode = p.TransactionCode
+		if ed.TransactionCode == 0 {
+			ed.TransactionCode = ach.CheckingCredit
+		}
+		ed.SetRDFI(p.RDFIIdentification)
+		ed.DFIAccountNumber = p.DFIAccountNumber
+		ed.Amount = p.Amount
+		ed.IndividualName = p.ReceivingCompany
+		ed.SetTraceNumber(header.ODFIIdentification, i+1)
+		ed.AddAddenda05(addenda05)
+		ed.AddendaRecordIndicator = 1
+		batch.AddEntry(ed)
+	}
+	if err := batch.Create(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
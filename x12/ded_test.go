@@ -0,0 +1,118 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/stretchr/testify/require"
+)
+
+func mockDED() DED {
+	return DED{
+		CaseID:   "CASE123456",
+		PayDate:  "260415",
+		Amount:   25000,
+		SSN:      "123456789",
+		Name:     "SMITH JO",
+		FIPSCode: "06037",
+	}
+}
+
+func TestDED(t *testing.T) {
+	ded := mockDED()
+	a, err := DEDAddenda(ded)
+	require.NoError(t, err)
+	require.Equal(t, `DED*CS*CASE123456*260415*25000*123456789*N*SMITH JO*06037*N\`, a.PaymentRelatedInformation)
+
+	read, err := ParseDED(a)
+	require.NoError(t, err)
+	require.Equal(t, ded, *read)
+
+	ded.MedicalSupport, ded.EmploymentTerminated = true, true
+	ded.FIPSCode = ""
+	a, err = DEDAddenda(ded)
+	require.NoError(t, err)
+	require.Equal(t, `DED*CS*CASE123456*260415*25000*123456789*Y*SMITH JO**Y\`, a.PaymentRelatedInformation)
+	read, err = ParseDED(a)
+	require.NoError(t, err)
+	require.Equal(t, ded, *read)
+
+	a.PaymentRelatedInformation = `DED*XX*CASE123456*260415*25000*123456789*N*SMITH JO\`
+	_, err = ParseDED(a)
+	require.ErrorContains(t, err, "DED01")
+}
+
+func TestDED__Validate(t *testing.T) {
+	cases := []func(*DED){
+		func(d *DED) { d.CaseID = "" },
+		func(d *DED) { d.PayDate = "260431" },
+		func(d *DED) { d.Amount = 0 },
+		func(d *DED) { d.SSN = "12345678X" },
+		func(d *DED) { d.Name = "JONATHAN SMITH" },
+		func(d *DED) { d.FIPSCode = "060" },
+	}
+	for i, fn := range cases {
+		ded := mockDED()
+		fn(&ded)
+		_, err := DEDAddenda(ded)
+		require.ErrorIs(t, err, ErrSegmentSyntax, "case %d", i)
+	}
+}
+
+func TestChildSupportBatch(t *testing.T) {
+	bh := ach.NewBatchHeader()
+	bh.CompanyName = "Employer Inc"
+	bh.CompanyIdentification = "1234567890"
+	bh.EffectiveEntryDate = "260416"
+	bh.ODFIIdentification = "12104288"
+
+	second := mockDED()
+	second.CaseID = "CASE654321"
+	second.Amount = 12550
+	payments := []ChildSupportPayment{
+		{DED: mockDED(), RDFIIdentification: "231380104", DFIAccountNumber: "987654321", ReceivingCompany: "State SDU"},
+		{DED: second, RDFIIdentification: "231380104", DFIAccountNumber: "987654321", ReceivingCompany: "State SDU", TransactionCode: ach.SavingsCredit},
+	}
+	batch, err := ChildSupportBatch(bh, payments)
+	require.NoError(t, err)
+	require.Equal(t, ach.CCD, batch.GetHeader().StandardEntryClassCode)
+	require.Equal(t, ChildSupportEntryDescription, batch.GetHeader().CompanyEntryDescription)
+	require.Empty(t, bh.CompanyEntryDescription)
+	require.Equal(t, 37550, batch.GetControl().TotalCreditEntryDollarAmount)
+
+	entries := batch.GetEntries()
+	require.Len(t, entries, 2)
+	require.Equal(t, ach.CheckingCredit, entries[0].TransactionCode)
+	require.Equal(t, ach.SavingsCredit, entries[1].TransactionCode)
+	require.Equal(t, "121042880000002", entries[1].TraceNumber)
+
+	ded, err := ParseDED(entries[1].Addenda05[0])
+	require.NoError(t, err)
+	require.Equal(t, "CASE654321", ded.CaseID)
+
+	payments[1].SSN = ""
+	_, err = ChildSupportBatch(bh, payments)
+	require.ErrorContains(t, err, "child support payment 2")
+
+	_, err = ChildSupportBatch(bh, nil)
+	require.Error(t, err)
+	_, err = ChildSupportBatch(nil, payments)
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moov-io/ach"
+)
+
+// HealthcareEntryDescription is the CompanyEntryDescription of healthcare claim payment batches
+const HealthcareEntryDescription = "HCCLAIMPMT"
+
+// ValidateHealthcare checks the TRN follows the Healthcare EFT Standard's reassociation trace:
+// TRN01 is 1, TRN02 is the EFT trace number, TRN03 is 1 followed by the payer's 9 digit EIN
+// and the optional TRN04 is at most 50 characters.
+func (t TRN) ValidateHealthcare() error {
+	if t.TraceTypeCode != "1" {
+		return fmt.Errorf("%w: TRN01 trace type code must be 1", ErrSegmentSyntax)
+	}
+	if t.ReferenceID == "" || len(t.ReferenceID) > 50 {
+		return fmt.Errorf("%w: TRN02 EFT trace number %q", ErrSegmentSyntax, t.ReferenceID)
+	}
+	id := t.OriginatingCompanyID
+	if len(id) != 10 || id[0] != '1' || strings.Trim(id, "0123456789") != "" {
+		return fmt.Errorf("%w: TRN03 payer identifier %q must be 1 followed by a 9 digit EIN", ErrSegmentSyntax, id)
+	}
+	if len(t.SecondaryReferenceID) > 50 {
+		return fmt.Errorf("%w: TRN04 is over 50 characters", ErrSegmentSyntax)
+	}
+	return nil
+}
+
+// HealthcareTRNAddenda returns an Addenda05 with a healthcare reassociation TRN segment,
+// e.g. TRN*1*12345*1512345678\
+func HealthcareTRNAddenda(t TRN) (*ach.Addenda05, error) {
+	if err := t.ValidateHealthcare(); err != nil {
+		return nil, err
+	}
+	return SegmentAddenda(t.Segment())
+}
+
+// ParseHealthcareTRN reads and validates the healthcare reassociation TRN segment of an Addenda05.
+func ParseHealthcareTRN(a *ach.Addenda05) (*TRN, error) {
+	t, err := ParseTRN(a)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ValidateHealthcare(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reassociation links a received healthcare EFT to the X12 835 remittance advice with the same
+// EFT trace number and payer identifier.
+type Reassociation struct {
+	// EFTTraceNumber is TRN02, the 835's check or EFT trace number
+	EFTTraceNumber string `json:"eftTraceNumber"`
+	// PayerIdentifier is TRN03, 1 followed by the payer's EIN
+	PayerIdentifier string `json:"payerIdentifier"`
+	// SupplementalCode is TRN04, if any
+	SupplementalCode string `json:"supplementalCode,omitempty"`
+
+	PayerName          string `json:"payerName"`
+	EffectiveEntryDate string `json:"effectiveEntryDate"`
+	TraceNumber        string `json:"traceNumber"`
+	Amount             int    `json:"amount"`
+}
+
+// Reassociations are the healthcare EFTs received in files.
+type Reassociations []Reassociation
+
+// Find returns the EFT with a TRN02 EFT trace number and TRN03 payer identifier.
+func (r Reassociations) Find(eftTraceNumber, payerIdentifier string) *Reassociation {
+	for i := range r {
+		if r[i].EFTTraceNumber == eftTraceNumber && r[i].PayerIdentifier == payerIdentifier {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// FindReassociations extracts the reassociation trace from the entries of CCD batches in files.
+// Entries without a TRN Addenda05 are skipped. Reassociations with a malformed TRN aren't returned
+// and the first problem is returned as an error after every file is read.
+func FindReassociations(files ...*ach.File) (Reassociations, error) {
+	var out Reassociations
+	var firstErr error
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		for _, b := range file.Batches {
+			bh := b.GetHeader()
+			if bh.StandardEntryClassCode != ach.CCD {
+				continue
+			}
+			for _, ed := range b.GetEntries() {
+				if len(ed.Addenda05) == 0 || !strings.HasPrefix(ed.Addenda05[0].PaymentRelatedInformation, "TRN") {
+					continue
+				}
+				t, err := ParseHealthcareTRN(ed.Addenda05[0])
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("entry %s: %w", ed.TraceNumber, err)
+					}
+					continue
+				}
+				out = append(out, Reassociation{
+					EFTTraceNumber:     t.ReferenceID,
+					PayerIdentifier:    t.OriginatingCompanyID,
+					SupplementalCode:   t.SecondaryReferenceID,
+					PayerName:          strings.TrimSpace(bh.CompanyName),
+					EffectiveEntryDate: bh.EffectiveEntryDate,
+					TraceNumber:        ed.TraceNumber,
+					Amount:             ed.Amount,
+				})
+			}
+		}
+	}
+	return out, firstErr
+}
@@ -0,0 +1,122 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/stretchr/testify/require"
+)
+
+func mockHealthcareTRN() TRN {
+	return TRN{TraceTypeCode: "1", ReferenceID: "EFT0012345", OriginatingCompanyID: "1512345678"}
+}
+
+func TestHealthcareTRN(t *testing.T) {
+	trn := mockHealthcareTRN()
+	a, err := HealthcareTRNAddenda(trn)
+	require.NoError(t, err)
+	require.Equal(t, `TRN*1*EFT0012345*1512345678\`, a.PaymentRelatedInformation)
+
+	read, err := ParseHealthcareTRN(a)
+	require.NoError(t, err)
+	require.Equal(t, trn, *read)
+
+	trn.SecondaryReferenceID = "999999999"
+	a, err = HealthcareTRNAddenda(trn)
+	require.NoError(t, err)
+	require.Equal(t, `TRN*1*EFT0012345*1512345678*999999999\`, a.PaymentRelatedInformation)
+
+	a.PaymentRelatedInformation = `TRN*1*EFT0012345*512345678\`
+	_, err = ParseHealthcareTRN(a)
+	require.ErrorContains(t, err, "TRN03")
+
+	a.PaymentRelatedInformation = `RMR*IV*12345**100.00\`
+	_, err = ParseHealthcareTRN(a)
+	require.Error(t, err)
+}
+
+func TestHealthcareTRN__Validate(t *testing.T) {
+	cases := []func(*TRN){
+		func(trn *TRN) { trn.TraceTypeCode = "2" },
+		func(trn *TRN) { trn.ReferenceID = "" },
+		func(trn *TRN) { trn.OriginatingCompanyID = "2512345678" },
+		func(trn *TRN) { trn.OriginatingCompanyID = "151234567X" },
+		func(trn *TRN) { trn.SecondaryReferenceID = "123456789012345678901234567890123456789012345678901" },
+	}
+	for i, fn := range cases {
+		trn := mockHealthcareTRN()
+		fn(&trn)
+		require.ErrorIs(t, trn.ValidateHealthcare(), ErrSegmentSyntax, "case %d", i)
+	}
+}
+
+func TestFindReassociations(t *testing.T) {
+	bh := ach.NewBatchHeader()
+	bh.ServiceClassCode = ach.CreditsOnly
+	bh.CompanyName = "Health Payer"
+	bh.CompanyIdentification = "1512345678"
+	bh.StandardEntryClassCode = ach.CCD
+	bh.CompanyEntryDescription = HealthcareEntryDescription
+	bh.EffectiveEntryDate = "260416"
+	bh.ODFIIdentification = "12104288"
+
+	batch := ach.NewBatchCCD(bh)
+	for i, info := range []string{`TRN*1*EFT0012345*1512345678\`, `RMR*IV*12345**100.00\`, ""} {
+		ed := ach.NewEntryDetail()
+		ed.TransactionCode = ach.CheckingCredit
+		ed.SetRDFI("231380104")
+		ed.DFIAccountNumber = "987654321"
+		ed.Amount = 150000 + i
+		ed.SetReceivingCompany("Provider Clinic")
+		ed.SetTraceNumber(bh.ODFIIdentification, i+1)
+		if info != "" {
+			ed.AddendaRecordIndicator = 1
+			a := ach.NewAddenda05()
+			a.PaymentRelatedInformation = info
+			ed.AddAddenda05(a)
+		}
+		batch.AddEntry(ed)
+	}
+	require.NoError(t, batch.Create())
+
+	file := ach.NewFile()
+	file.AddBatch(batch)
+
+	found, err := FindReassociations(file, nil)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, Reassociation{
+		EFTTraceNumber:     "EFT0012345",
+		PayerIdentifier:    "1512345678",
+		PayerName:          "Health Payer",
+		EffectiveEntryDate: "260416",
+		TraceNumber:        "121042880000001",
+		Amount:             150000,
+	}, found[0])
+
+	require.Equal(t, &found[0], found.Find("EFT0012345", "1512345678"))
+	require.Nil(t, found.Find("EFT0012345", "1999999999"))
+
+	// malformed traces are reported but don't stop the lookup
+	batch.GetEntries()[1].Addenda05[0].PaymentRelatedInformation = `TRN*3*EFT0012346*1512345678\`
+	found, err = FindReassociations(file)
+	require.ErrorContains(t, err, "entry 121042880000002")
+	require.Len(t, found, 1)
+}
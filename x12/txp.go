@@ -0,0 +1,163 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moov-io/ach"
+)
+
+// Amount types of federal tax payments
+const (
+	TXPTax      = "T"
+	TXPPenalty  = "P"
+	TXPInterest = "I"
+)
+
+// maxTXPAmounts is how many amount type and amount pairs a TXP segment holds
+const maxTXPAmounts = 3
+
+// ErrTXPAmountMismatch is the error given when a TXP segment's amounts don't total the entry's Amount
+var ErrTXPAmountMismatch = errors.New("x12: TXP amounts don't equal the entry amount")
+
+// TXPAmount is an amount type and its amount in cents.
+type TXPAmount struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+}
+
+// TXP is the Tax Payment banking convention carried in the Addenda05 of a CCD+ tax payment, e.g.
+//
+//	TXP*123456789*94105*260331*T*150000*P*2500*I*1000\
+type TXP struct {
+	// TaxpayerID is the taxpayer's EIN, SSN or state assigned identification number
+	TaxpayerID string `json:"taxpayerID"`
+	// TaxTypeCode is the tax authority's code for the tax being paid, e.g. 94105 for a federal Form 941
+	TaxTypeCode string `json:"taxTypeCode"`
+	// PeriodEndDate is the YYMMDD end of the tax period
+	PeriodEndDate string `json:"periodEndDate"`
+	// Amounts are up to three amounts, such as tax, penalty and interest
+	Amounts []TXPAmount `json:"amounts"`
+	// VerificationCode is an optional taxpayer verification or PIN
+	VerificationCode string `json:"verificationCode,omitempty"`
+}
+
+// Total returns the sum of the TXP amounts in cents.
+func (t TXP) Total() int {
+	var total int
+	for _, a := range t.Amounts {
+		total += a.Amount
+	}
+	return total
+}
+
+// Validate checks the TXP fields are present and well formed.
+func (t TXP) Validate() error {
+	if t.TaxpayerID == "" || len(t.TaxpayerID) > 15 {
+		return fmt.Errorf("%w: TXP01 taxpayer ID %q", ErrSegmentSyntax, t.TaxpayerID)
+	}
+	if t.TaxTypeCode == "" || len(t.TaxTypeCode) > 5 {
+		return fmt.Errorf("%w: TXP02 tax type code %q", ErrSegmentSyntax, t.TaxTypeCode)
+	}
+	if _, err := time.Parse("060102", t.PeriodEndDate); err != nil {
+		return fmt.Errorf("%w: TXP03 period end date %q", ErrSegmentSyntax, t.PeriodEndDate)
+	}
+	if len(t.Amounts) == 0 || len(t.Amounts) > maxTXPAmounts {
+		return fmt.Errorf("%w: TXP has %d amounts, 1 to %d are required", ErrSegmentSyntax, len(t.Amounts), maxTXPAmounts)
+	}
+	for i, a := range t.Amounts {
+		if len(a.Type) != 1 {
+			return fmt.Errorf("%w: TXP%02d amount type %q", ErrSegmentSyntax, 4+i*2, a.Type)
+		}
+		if a.Amount < 0 || a.Amount > 9999999999 {
+			return fmt.Errorf("%w: TXP%02d amount %d", ErrSegmentSyntax, 5+i*2, a.Amount)
+		}
+	}
+	return nil
+}
+
+// Segment returns the TXP segment. Amounts are written in cents without a decimal point.
+func (t TXP) Segment() Segment {
+	elements := make([]string, 9, 10)
+	elements[0], elements[1], elements[2] = t.TaxpayerID, t.TaxTypeCode, t.PeriodEndDate
+	for i, a := range t.Amounts {
+		if i >= maxTXPAmounts {
+			break
+		}
+		elements[3+i*2] = a.Type
+		elements[4+i*2] = strconv.Itoa(a.Amount)
+	}
+	elements = append(elements, t.VerificationCode)
+	return Segment{ID: "TXP", Elements: elements}
+}
+
+// TXPAddenda validates the TXP and returns an Addenda05 with its segment.
+func TXPAddenda(t TXP) (*ach.Addenda05, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return SegmentAddenda(t.Segment())
+}
+
+// ParseTXP reads and validates the TXP segment of an Addenda05.
+func ParseTXP(a *ach.Addenda05) (*TXP, error) {
+	seg, err := parseAddendaSegment(a, "TXP")
+	if err != nil {
+		return nil, err
+	}
+	t := &TXP{
+		TaxpayerID:       seg.Element(1),
+		TaxTypeCode:      seg.Element(2),
+		PeriodEndDate:    seg.Element(3),
+		VerificationCode: seg.Element(10),
+	}
+	for i := 0; i < maxTXPAmounts; i++ {
+		typ, amount := seg.Element(4+i*2), seg.Element(5+i*2)
+		if typ == "" && amount == "" {
+			continue
+		}
+		n, err := strconv.Atoi(amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: TXP%02d amount %q", ErrSegmentSyntax, 5+i*2, amount)
+		}
+		t.Amounts = append(t.Amounts, TXPAmount{Type: typ, Amount: n})
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CheckTXP reads the TXP segment of an entry's Addenda05 and checks its amounts total the entry's Amount.
+func CheckTXP(ed *ach.EntryDetail) (*TXP, error) {
+	if ed == nil || len(ed.Addenda05) == 0 {
+		return nil, errors.New("x12: entry has no Addenda05")
+	}
+	t, err := ParseTXP(ed.Addenda05[0])
+	if err != nil {
+		return nil, err
+	}
+	if total := t.Total(); total != ed.Amount {
+		return t, fmt.Errorf("%w: TXP total %d, entry amount %d", ErrTXPAmountMismatch, total, ed.Amount)
+	}
+	return t, nil
+}
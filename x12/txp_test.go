@@ -0,0 +1,106 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTXP(t *testing.T) {
+	txp := TXP{
+		TaxpayerID:    "123456789",
+		TaxTypeCode:   "94105",
+		PeriodEndDate: "260331",
+		Amounts: []TXPAmount{
+			{Type: TXPTax, Amount: 150000},
+			{Type: TXPPenalty, Amount: 2500},
+			{Type: TXPInterest, Amount: 1000},
+		},
+	}
+	a, err := TXPAddenda(txp)
+	require.NoError(t, err)
+	require.Equal(t, `TXP*123456789*94105*260331*T*150000*P*2500*I*1000\`, a.PaymentRelatedInformation)
+
+	read, err := ParseTXP(a)
+	require.NoError(t, err)
+	require.Equal(t, txp, *read)
+	require.Equal(t, 153500, read.Total())
+
+	// a single amount with a verification code keeps the empty positions
+	txp.Amounts = txp.Amounts[:1]
+	txp.VerificationCode = "1234"
+	a, err = TXPAddenda(txp)
+	require.NoError(t, err)
+	require.Equal(t, `TXP*123456789*94105*260331*T*150000*****1234\`, a.PaymentRelatedInformation)
+	read, err = ParseTXP(a)
+	require.NoError(t, err)
+	require.Equal(t, txp, *read)
+}
+
+func TestTXP__Validate(t *testing.T) {
+	valid := func() TXP {
+		return TXP{TaxpayerID: "123456789", TaxTypeCode: "94105", PeriodEndDate: "260331", Amounts: []TXPAmount{{Type: "T", Amount: 1}}}
+	}
+	require.NoError(t, valid().Validate())
+
+	cases := []func(*TXP){
+		func(t *TXP) { t.TaxpayerID = "" },
+		func(t *TXP) { t.TaxTypeCode = "941050" },
+		func(t *TXP) { t.PeriodEndDate = "261331" },
+		func(t *TXP) { t.Amounts = nil },
+		func(t *TXP) { t.Amounts = append(t.Amounts, t.Amounts[0], t.Amounts[0], t.Amounts[0]) },
+		func(t *TXP) { t.Amounts[0].Type = "" },
+		func(t *TXP) { t.Amounts[0].Amount = -1 },
+	}
+	for i, fn := range cases {
+		txp := valid()
+		fn(&txp)
+		_, err := TXPAddenda(txp)
+		require.ErrorIs(t, err, ErrSegmentSyntax, "case %d", i)
+	}
+
+	a := ach.NewAddenda05()
+	a.PaymentRelatedInformation = `TXP*123456789*94105*260331*T*1.50\`
+	_, err := ParseTXP(a)
+	require.ErrorContains(t, err, "TXP05 amount")
+}
+
+func TestCheckTXP(t *testing.T) {
+	a, err := TXPAddenda(TXP{TaxpayerID: "123456789", TaxTypeCode: "94105", PeriodEndDate: "260331", Amounts: []TXPAmount{
+		{Type: TXPTax, Amount: 150000},
+		{Type: TXPInterest, Amount: 1000},
+	}})
+	require.NoError(t, err)
+
+	ed := ach.NewEntryDetail()
+	ed.Amount = 151000
+	ed.AddAddenda05(a)
+	txp, err := CheckTXP(ed)
+	require.NoError(t, err)
+	require.Equal(t, "94105", txp.TaxTypeCode)
+
+	ed.Amount = 150000
+	_, err = CheckTXP(ed)
+	require.ErrorIs(t, err, ErrTXPAmountMismatch)
+
+	_, err = CheckTXP(ach.NewEntryDetail())
+	require.Error(t, err)
+}
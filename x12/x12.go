@@ -0,0 +1,501 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package x12 reads and writes the ANSI ASC X12 820 Payment Order/Remittance Advice
+// transactions carried in the Addenda05 records of CTX entries.
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrMissingISA is the error given when data doesn't begin with an ISA segment
+	ErrMissingISA = errors.New("x12: missing ISA interchange header")
+	// ErrUnexpectedSegment is the error given when a segment is outside of its envelope
+	ErrUnexpectedSegment = errors.New("x12: unexpected segment")
+)
+
+// isaLength is the fixed length of an ISA segment, excluding its terminator
+const isaLength = 105
+
+// Delimiters separate the segments, elements and components of an interchange. They're
+// read from the ISA segment.
+type Delimiters struct {
+	Element   byte
+	Component byte
+	Segment   byte
+}
+
+// DefaultDelimiters are used when writing an Interchange without Delimiters.
+var DefaultDelimiters = Delimiters{
+	Element:   '*',
+	Component: '>',
+	Segment:   '\\',
+}
+
+// Segment is a segment ID and its elements.
+type Segment struct {
+	ID       string
+	Elements []string
+}
+
+// Element returns the element at a 1-based position, or an empty string when the segment is shorter.
+func (s Segment) Element(n int) string {
+	if n < 1 || n > len(s.Elements) {
+		return ""
+	}
+	return s.Elements[n-1]
+}
+
+// String writes the segment with its elements, omitting trailing empty elements.
+func (s Segment) String(d Delimiters) string {
+	elements := s.Elements
+	for len(elements) > 0 && elements[len(elements)-1] == "" {
+		elements = elements[:len(elements)-1]
+	}
+	var buf strings.Builder
+	buf.WriteString(s.ID)
+	for _, e := range elements {
+		buf.WriteByte(d.Element)
+		buf.WriteString(e)
+	}
+	buf.WriteByte(d.Segment)
+	return buf.String()
+}
+
+// ParseSegments splits data into segments. Whitespace between segments is ignored.
+func ParseSegments(data string, d Delimiters) []Segment {
+	var segments []Segment
+	for _, raw := range strings.Split(data, string(d.Segment)) {
+		raw = strings.Trim(raw, "\r\n")
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		parts := strings.Split(raw, string(d.Element))
+		segments = append(segments, Segment{
+			ID:       strings.TrimSpace(parts[0]),
+			Elements: parts[1:],
+		})
+	}
+	return segments
+}
+
+// Interchange is an ISA/IEA envelope of functional groups.
+type Interchange struct {
+	Header     ISA
+	Groups     []*FunctionalGroup
+	Delimiters Delimiters
+}
+
+// ISA is the interchange control header.
+type ISA struct {
+	AuthorizationQualifier   string
+	AuthorizationInformation string
+	SecurityQualifier        string
+	SecurityInformation      string
+	SenderQualifier          string
+	SenderID                 string
+	ReceiverQualifier        string
+	ReceiverID               string
+	// Date is formatted YYMMDD
+	Date string
+	// Time is formatted HHMM
+	Time                string
+	RepetitionSeparator string
+	Version             string
+	ControlNumber       string
+	AckRequested        string
+	// UsageIndicator is P for production and T for test data
+	UsageIndicator string
+}
+
+// FunctionalGroup is a GS/GE envelope of transaction sets.
+type FunctionalGroup struct {
+	// FunctionalIdentifierCode is RA for 820 transactions
+	FunctionalIdentifierCode string
+	SenderCode               string
+	ReceiverCode             string
+	// Date is formatted CCYYMMDD
+	Date                  string
+	Time                  string
+	ControlNumber         string
+	ResponsibleAgencyCode string
+	Version               string
+
+	Transactions []*Transaction
+}
+
+// Transaction is an 820 Payment Order/Remittance Advice transaction set.
+//
+// Segments other than BPR, TRN, REF, DTM, ENT and RMR (such as N1) are kept in Other
+// and written back where they were read. Other segments added after parsing are written
+// at the end of the transaction set.
+type Transaction struct {
+	// Code is the transaction set identifier, 820
+	Code          string
+	ControlNumber string
+
+	Payment BPR
+	Trace   *TRN
+	// References and Dates are the REF and DTM segments before the first RMR segment
+	References  []REF
+	Dates       []DTM
+	Remittances []*Remittance
+
+	Other []Segment
+
+	// entity is the current ENT assigned number while parsing
+	entity string
+	// parsed counts the segments after ST while parsing and otherAt holds the position of each Other segment
+	parsed  int
+	otherAt []int
+}
+
+// BPR is the Beginning Segment for Payment Order/Remittance Advice.
+type BPR struct {
+	TransactionHandlingCode string
+	// Amount is the total payment amount, e.g. 1250.75
+	Amount string
+	// CreditDebitFlag is C for credit and D for debit
+	CreditDebitFlag                    string
+	PaymentMethodCode                  string
+	PaymentFormatCode                  string
+	OriginatingDFIQualifier            string
+	OriginatingDFIID                   string
+	OriginatingAccountQualifier        string
+	OriginatingAccountNumber           string
+	OriginatingCompanyID               string
+	OriginatingCompanySupplementalCode string
+	ReceivingDFIQualifier              string
+	ReceivingDFIID                     string
+	ReceivingAccountQualifier          string
+	ReceivingAccountNumber             string
+	// EffectiveDate is formatted CCYYMMDD
+	EffectiveDate string
+}
+
+// TRN is the Trace segment which reassociates a payment with its remittance.
+type TRN struct {
+	TraceTypeCode        string
+	ReferenceID          string
+	OriginatingCompanyID string
+	SecondaryReferenceID string
+}
+
+// REF is a Reference Information segment.
+type REF struct {
+	Qualifier   string
+	ID          string
+	Description string
+}
+
+// DTM is a Date/Time Reference segment.
+type DTM struct {
+	Qualifier string
+	// Date is formatted CCYYMMDD
+	Date string
+	Time string
+}
+
+// RMR is a Remittance Advice Accounts Receivable Open Item Reference segment.
+type RMR struct {
+	Qualifier         string
+	ReferenceID       string
+	PaymentActionCode string
+	// Amount is the amount paid
+	Amount         string
+	InvoiceAmount  string
+	DiscountAmount string
+}
+
+// Remittance is an RMR segment with the REF and DTM segments following it.
+type Remittance struct {
+	// Entity is the assigned number of the ENT segment the remittance follows, if any
+	Entity string
+	RMR
+	References []REF
+	Dates      []DTM
+}
+
+// Parse reads an interchange. The delimiters are read from the ISA segment.
+func Parse(data string) (*Interchange, error) {
+	data = strings.TrimLeft(data, " \r\n")
+	if !strings.HasPrefix(data, "ISA") || len(data) <= isaLength {
+		return nil, ErrMissingISA
+	}
+	d := Delimiters{
+		Element:   data[3],
+		Component: data[isaLength-1],
+		Segment:   data[isaLength],
+	}
+
+	ic := &Interchange{Delimiters: d}
+	var group *FunctionalGroup
+	var txn *Transaction
+	var remittance *Remittance
+	var closed bool
+
+	for _, seg := range ParseSegments(data, d) {
+		if closed {
+			return nil, fmt.Errorf("%w: %s after IEA", ErrUnexpectedSegment, seg.ID)
+		}
+		switch seg.ID {
+		case "ISA":
+			ic.Header = parseISA(seg)
+		case "IEA":
+			closed = true
+		case "GS":
+			group = &FunctionalGroup{
+				FunctionalIdentifierCode: seg.Element(1),
+				SenderCode:               seg.Element(2),
+				ReceiverCode:             seg.Element(3),
+				Date:                     seg.Element(4),
+				Time:                     seg.Element(5),
+				ControlNumber:            seg.Element(6),
+				ResponsibleAgencyCode:    seg.Element(7),
+				Version:                  seg.Element(8),
+			}
+			ic.Groups = append(ic.Groups, group)
+		case "GE":
+			group = nil
+		case "ST":
+			if group == nil {
+				return nil, fmt.Errorf("%w: ST outside of GS", ErrUnexpectedSegment)
+			}
+			txn = &Transaction{Code: seg.Element(1), ControlNumber: seg.Element(2)}
+			remittance = nil
+			group.Transactions = append(group.Transactions, txn)
+		case "SE":
+			txn = nil
+		default:
+			if txn == nil {
+				return nil, fmt.Errorf("%w: %s outside of ST", ErrUnexpectedSegment, seg.ID)
+			}
+			remittance = txn.add(seg, remittance)
+		}
+	}
+	return ic, nil
+}
+
+// add parses a segment inside of a transaction set and returns the current remittance
+func (t *Transaction) add(seg Segment, remittance *Remittance) *Remittance {
+	t.parsed++
+	switch seg.ID {
+	case "ENT":
+		t.entity = seg.Element(1)
+		return nil
+	case "BPR":
+		t.Payment = BPR{
+			TransactionHandlingCode:            seg.Element(1),
+			Amount:                             seg.Element(2),
+			CreditDebitFlag:                    seg.Element(3),
+			PaymentMethodCode:                  seg.Element(4),
+			PaymentFormatCode:                  seg.Element(5),
+			OriginatingDFIQualifier:            seg.Element(6),
+			OriginatingDFIID:                   seg.Element(7),
+			OriginatingAccountQualifier:        seg.Element(8),
+			OriginatingAccountNumber:           seg.Element(9),
+			OriginatingCompanyID:               seg.Element(10),
+			OriginatingCompanySupplementalCode: seg.Element(11),
+			ReceivingDFIQualifier:              seg.Element(12),
+			ReceivingDFIID:                     seg.Element(13),
+			ReceivingAccountQualifier:          seg.Element(14),
+			ReceivingAccountNumber:             seg.Element(15),
+			EffectiveDate:                      seg.Element(16),
+		}
+	case "TRN":
+		t.Trace = &TRN{
+			TraceTypeCode:        seg.Element(1),
+			ReferenceID:          seg.Element(2),
+			OriginatingCompanyID: seg.Element(3),
+			SecondaryReferenceID: seg.Element(4),
+		}
+	case "RMR":
+		remittance = &Remittance{Entity: t.entity, RMR: RMR{
+			Qualifier:         seg.Element(1),
+			ReferenceID:       seg.Element(2),
+			PaymentActionCode: seg.Element(3),
+			Amount:            seg.Element(4),
+			InvoiceAmount:     seg.Element(5),
+			DiscountAmount:    seg.Element(6),
+		}}
+		t.Remittances = append(t.Remittances, remittance)
+	case "REF":
+		ref := REF{Qualifier: seg.Element(1), ID: seg.Element(2), Description: seg.Element(3)}
+		if remittance != nil {
+			remittance.References = append(remittance.References, ref)
+		} else {
+			t.References = append(t.References, ref)
+		}
+	case "DTM":
+		dtm := DTM{Qualifier: seg.Element(1), Date: seg.Element(2), Time: seg.Element(3)}
+		if remittance != nil {
+			remittance.Dates = append(remittance.Dates, dtm)
+		} else {
+			t.Dates = append(t.Dates, dtm)
+		}
+	default:
+		t.Other = append(t.Other, seg)
+		t.otherAt = append(t.otherAt, t.parsed)
+	}
+	return remittance
+}
+
+func parseISA(seg Segment) ISA {
+	field := func(n int) string {
+		return strings.TrimSpace(seg.Element(n))
+	}
+	return ISA{
+		AuthorizationQualifier:   field(1),
+		AuthorizationInformation: field(2),
+		SecurityQualifier:        field(3),
+		SecurityInformation:      field(4),
+		SenderQualifier:          field(5),
+		SenderID:                 field(6),
+		ReceiverQualifier:        field(7),
+		ReceiverID:               field(8),
+		Date:                     field(9),
+		Time:                     field(10),
+		RepetitionSeparator:      field(11),
+		Version:                  field(12),
+		ControlNumber:            field(13),
+		AckRequested:             field(14),
+		UsageIndicator:           field(15),
+	}
+}
+
+// String writes the interchange with its Delimiters, or DefaultDelimiters when they're unset.
+// Segment counts and group and transaction counts are calculated.
+func (ic *Interchange) String() string {
+	d := ic.Delimiters
+	if d.Element == 0 || d.Component == 0 || d.Segment == 0 {
+		d = DefaultDelimiters
+	}
+
+	var buf strings.Builder
+	buf.WriteString(ic.Header.segment(d).String(d))
+	for _, g := range ic.Groups {
+		buf.WriteString(Segment{ID: "GS", Elements: []string{
+			g.FunctionalIdentifierCode, g.SenderCode, g.ReceiverCode, g.Date, g.Time,
+			g.ControlNumber, g.ResponsibleAgencyCode, g.Version,
+		}}.String(d))
+		for _, t := range g.Transactions {
+			segments := t.segments()
+			for _, seg := range segments {
+				buf.WriteString(seg.String(d))
+			}
+			// SE counts every segment including ST and SE
+			buf.WriteString(Segment{ID: "SE", Elements: []string{fmt.Sprintf("%d", len(segments)+1), t.ControlNumber}}.String(d))
+		}
+		buf.WriteString(Segment{ID: "GE", Elements: []string{fmt.Sprintf("%d", len(g.Transactions)), g.ControlNumber}}.String(d))
+	}
+	buf.WriteString(Segment{ID: "IEA", Elements: []string{fmt.Sprintf("%d", len(ic.Groups)), pad(ic.Header.ControlNumber, 9, '0', true)}}.String(d))
+	return buf.String()
+}
+
+// segment returns the ISA segment with its fixed width elements
+func (h ISA) segment(d Delimiters) Segment {
+	repetition := h.RepetitionSeparator
+	if repetition == "" {
+		repetition = "U"
+	}
+	return Segment{ID: "ISA", Elements: []string{
+		pad(h.AuthorizationQualifier, 2, '0', true),
+		pad(h.AuthorizationInformation, 10, ' ', false),
+		pad(h.SecurityQualifier, 2, '0', true),
+		pad(h.SecurityInformation, 10, ' ', false),
+		pad(h.SenderQualifier, 2, ' ', false),
+		pad(h.SenderID, 15, ' ', false),
+		pad(h.ReceiverQualifier, 2, ' ', false),
+		pad(h.ReceiverID, 15, ' ', false),
+		pad(h.Date, 6, ' ', false),
+		pad(h.Time, 4, ' ', false),
+		repetition,
+		pad(h.Version, 5, '0', true),
+		pad(h.ControlNumber, 9, '0', true),
+		pad(h.AckRequested, 1, '0', true),
+		pad(h.UsageIndicator, 1, ' ', false),
+		string(d.Component),
+	}}
+}
+
+// segments returns the transaction set's segments from ST through the last remittance
+func (t *Transaction) segments() []Segment {
+	code := t.Code
+	if code == "" {
+		code = "820"
+	}
+	p := t.Payment
+	segments := []Segment{
+		{ID: "ST", Elements: []string{code, t.ControlNumber}},
+		{ID: "BPR", Elements: []string{
+			p.TransactionHandlingCode, p.Amount, p.CreditDebitFlag, p.PaymentMethodCode, p.PaymentFormatCode,
+			p.OriginatingDFIQualifier, p.OriginatingDFIID, p.OriginatingAccountQualifier, p.OriginatingAccountNumber,
+			p.OriginatingCompanyID, p.OriginatingCompanySupplementalCode,
+			p.ReceivingDFIQualifier, p.ReceivingDFIID, p.ReceivingAccountQualifier, p.ReceivingAccountNumber,
+			p.EffectiveDate,
+		}},
+	}
+	if t.Trace != nil {
+		segments = append(segments, t.Trace.Segment())
+	}
+	segments = appendReferences(segments, t.References, t.Dates)
+	var entity string
+	for _, r := range t.Remittances {
+		if r.Entity != "" && r.Entity != entity {
+			segments = append(segments, Segment{ID: "ENT", Elements: []string{r.Entity}})
+		}
+		entity = r.Entity
+		segments = append(segments, r.RMR.Segment())
+		segments = appendReferences(segments, r.References, r.Dates)
+	}
+
+	// put Other segments back at the position they were parsed from
+	for i, seg := range t.Other {
+		at := len(segments)
+		if i < len(t.otherAt) && t.otherAt[i] < at {
+			at = t.otherAt[i]
+		}
+		segments = append(segments[:at], append([]Segment{seg}, segments[at:]...)...)
+	}
+	return segments
+}
+
+func appendReferences(segments []Segment, refs []REF, dates []DTM) []Segment {
+	for _, ref := range refs {
+		segments = append(segments, ref.Segment())
+	}
+	for _, dtm := range dates {
+		segments = append(segments, Segment{ID: "DTM", Elements: []string{dtm.Qualifier, dtm.Date, dtm.Time}})
+	}
+	return segments
+}
+
+// pad fits s to a fixed width, padding on the left or right
+func pad(s string, width int, with byte, left bool) string {
+	if len(s) >= width {
+		return s[:width]
+	}
+	padding := strings.Repeat(string(with), width-len(s))
+	if left {
+		return padding + s
+	}
+	return s + padding
+}
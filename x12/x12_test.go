@@ -0,0 +1,196 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/stretchr/testify/require"
+)
+
+const sample820 = "ISA*00*          *00*          *ZZ*SENDERID       *ZZ*RECEIVERID     *260105*1200*U*00401*000000001*0*P*>\\" +
+	"GS*RA*SENDERID*RECEIVERID*20260105*1200*1*X*004010\\" +
+	"ST*820*0001\\" +
+	"BPR*C*1500.25*C*ACH*CTX*01*121042882*DA*123456789*1234567890**01*231380104*DA*987654321*20260106\\" +
+	"TRN*1*0000012345*1234567890\\" +
+	"REF*TN*0000012345\\" +
+	"DTM*097*20260105\\" +
+	"N1*PE*Receiver Company\\" +
+	"ENT*1\\" +
+	"RMR*IV*INV-1001*PI*1000.00*1000.00\\" +
+	"REF*PO*PO-77\\" +
+	"DTM*003*20251215\\" +
+	"RMR*IV*INV-1002*PI*500.25*520.25*20.00\\" +
+	"SE*12*0001\\" +
+	"GE*1*1\\" +
+	"IEA*1*000000001\\"
+
+func TestParse(t *testing.T) {
+	ic, err := Parse(sample820)
+	require.NoError(t, err)
+
+	require.Equal(t, byte('*'), ic.Delimiters.Element)
+	require.Equal(t, byte('>'), ic.Delimiters.Component)
+	require.Equal(t, byte('\\'), ic.Delimiters.Segment)
+	require.Equal(t, "SENDERID", ic.Header.SenderID)
+	require.Equal(t, "000000001", ic.Header.ControlNumber)
+	require.Equal(t, "P", ic.Header.UsageIndicator)
+
+	require.Len(t, ic.Groups, 1)
+	require.Equal(t, "RA", ic.Groups[0].FunctionalIdentifierCode)
+	require.Len(t, ic.Groups[0].Transactions, 1)
+
+	txn := ic.Groups[0].Transactions[0]
+	require.Equal(t, "820", txn.Code)
+	require.Equal(t, "1500.25", txn.Payment.Amount)
+	require.Equal(t, "CTX", txn.Payment.PaymentFormatCode)
+	require.Equal(t, "231380104", txn.Payment.ReceivingDFIID)
+	require.Equal(t, "20260106", txn.Payment.EffectiveDate)
+	require.Equal(t, "0000012345", txn.Trace.ReferenceID)
+	require.Equal(t, []REF{{Qualifier: "TN", ID: "0000012345"}}, txn.References)
+	require.Equal(t, []DTM{{Qualifier: "097", Date: "20260105"}}, txn.Dates)
+	require.Len(t, txn.Other, 1)
+	require.Equal(t, "Receiver Company", txn.Other[0].Element(2))
+
+	require.Len(t, txn.Remittances, 2)
+	first := txn.Remittances[0]
+	require.Equal(t, "1", first.Entity)
+	require.Equal(t, "INV-1001", first.ReferenceID)
+	require.Equal(t, "1000.00", first.Amount)
+	require.Equal(t, "PO-77", first.References[0].ID)
+	require.Equal(t, "20251215", first.Dates[0].Date)
+	require.Equal(t, "20.00", txn.Remittances[1].DiscountAmount)
+}
+
+func TestParse__Errors(t *testing.T) {
+	_, err := Parse("GS*RA\\")
+	require.ErrorIs(t, err, ErrMissingISA)
+
+	isa := sample820[:106]
+	_, err = Parse(isa + "ST*820*0001\\")
+	require.ErrorIs(t, err, ErrUnexpectedSegment)
+	_, err = Parse(isa + "BPR*C\\")
+	require.ErrorIs(t, err, ErrUnexpectedSegment)
+	_, err = Parse(isa + "IEA*0*000000001\\GS*RA\\")
+	require.True(t, errors.Is(err, ErrUnexpectedSegment))
+}
+
+func TestInterchange__String(t *testing.T) {
+	ic, err := Parse(sample820)
+	require.NoError(t, err)
+
+	// N1 segments are written back where they were read
+	written := ic.String()
+	require.Equal(t, sample820, written)
+
+	// and added Other segments are written at the end of the transaction set
+	txn := ic.Groups[0].Transactions[0]
+	txn.Other = append(txn.Other, Segment{ID: "NTE", Elements: []string{"ADD", "Thank you"}})
+	written = ic.String()
+	require.Contains(t, written, "RMR*IV*INV-1002*PI*500.25*520.25*20.00\\NTE*ADD*Thank you\\SE*13*0001\\")
+	txn.Other = txn.Other[:1]
+
+	// default delimiters
+	ic.Delimiters = Delimiters{}
+	ic.Header.ControlNumber = "42"
+	written = ic.String()
+	require.True(t, strings.HasPrefix(written, "ISA*00*          *00*"))
+	require.Contains(t, written, "*000000042*0*P*>\\GS")
+	require.True(t, strings.HasSuffix(written, "IEA*1*000000042\\"))
+
+	read, err := Parse(written)
+	require.NoError(t, err)
+	require.Equal(t, ic.Groups[0].Transactions[0].Remittances, read.Groups[0].Transactions[0].Remittances)
+}
+
+func TestAddenda(t *testing.T) {
+	ic, err := Parse(sample820)
+	require.NoError(t, err)
+
+	addenda := ToAddenda(ic)
+	require.Greater(t, len(addenda), 1)
+	for i, a := range addenda {
+		require.Equal(t, i+1, a.SequenceNumber)
+		require.LessOrEqual(t, len(a.PaymentRelatedInformation), 80)
+		require.Equal(t, strings.TrimSpace(a.PaymentRelatedInformation), a.PaymentRelatedInformation)
+	}
+
+	// addenda are reassembled in sequence order
+	addenda[0], addenda[1] = addenda[1], addenda[0]
+	read, err := FromAddenda(addenda)
+	require.NoError(t, err)
+	require.Equal(t, ic.String(), read.String())
+}
+
+func TestSetRemittance(t *testing.T) {
+	ic, err := Parse(sample820)
+	require.NoError(t, err)
+
+	bh := ach.NewBatchHeader()
+	bh.ServiceClassCode = ach.CreditsOnly
+	bh.CompanyName = "Payee Company"
+	bh.CompanyIdentification = "1234567890"
+	bh.StandardEntryClassCode = ach.CTX
+	bh.CompanyEntryDescription = "PAYMENT"
+	bh.EffectiveEntryDate = "260106"
+	bh.ODFIIdentification = "12104288"
+
+	ed := ach.NewEntryDetail()
+	ed.TransactionCode = ach.CheckingCredit
+	ed.SetRDFI("231380104")
+	ed.DFIAccountNumber = "987654321"
+	ed.Amount = 150025
+	ed.IdentificationNumber = "INV-1001"
+	ed.SetCATXReceivingCompany("Receiver Company")
+	ed.SetTraceNumber(bh.ODFIIdentification, 1)
+	require.NoError(t, SetRemittance(ed, ic))
+	require.Equal(t, 1, ed.AddendaRecordIndicator)
+	require.Equal(t, fmt.Sprintf("%04d", len(ed.Addenda05)), ed.CATXAddendaRecordsField())
+
+	batch := ach.NewBatchCTX(bh)
+	batch.AddEntry(ed)
+	require.NoError(t, batch.Create())
+
+	file := ach.NewFile()
+	file.Header = ach.NewFileHeader()
+	file.Header.ImmediateDestination = "231380104"
+	file.Header.ImmediateOrigin = "121042882"
+	file.Header.FileCreationDate = "260105"
+	file.Header.FileCreationTime = "1200"
+	file.Header.ImmediateDestinationName = "Receiver Bank"
+	file.Header.ImmediateOriginName = "Payee Bank"
+	file.AddBatch(batch)
+	require.NoError(t, file.Create())
+
+	var buf bytes.Buffer
+	require.NoError(t, ach.NewWriter(&buf).Write(file))
+
+	read, err := ach.NewReader(&buf).Read()
+	require.NoError(t, err)
+	entry := read.Batches[0].GetEntries()[0]
+	require.Len(t, entry.Addenda05, len(ed.Addenda05))
+
+	parsed, err := FromAddenda(entry.Addenda05)
+	require.NoError(t, err)
+	require.Equal(t, ic.String(), parsed.String())
+}